@@ -6,17 +6,19 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"syscall"
 
+	"opggvisualizer/internal/app"
 	"opggvisualizer/internal/cli"
 )
 
 func main() {
-	// Create a context that is cancelled on interrupt signal
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	// Create a context that is cancelled on interrupt signal, SIGTERM is sent by "server stop" and docker
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	// Initialize CLI commands
-	rootCmd := cli.NewRootCommand(ctx)
+	// Initialize CLI commands, the application container is built by app.New once the flags are parsed
+	rootCmd := cli.NewRootCommand(ctx, app.New)
 
 	// Execute the root command
 	if err := rootCmd.Execute(); err != nil {
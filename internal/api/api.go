@@ -4,92 +4,185 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
-	"opggvisualizer/internal/client"
-	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/app"
+	"opggvisualizer/internal/assets"
+	"opggvisualizer/internal/version"
+	"opggvisualizer/internal/web"
 )
 
-var server *http.Server // Global reference to the server
+// Server exposes the application over HTTP
+type Server struct {
+	app       *app.App
+	server    *http.Server
+	refresher *refresher
+	limiter   *rateLimiter
+	startedAt time.Time
+}
 
-func newServer() *http.Server {
-	if server == nil {
-		cfg := config.GetConfig()
-		server = &http.Server{Addr: ":" + cfg.APIServer.Port}
+func NewServer(application *app.App) *Server {
+	s := &Server{
+		app:       application,
+		refresher: newRefresher(application.Client),
+		limiter:   newRateLimiter(application.Config.APIServer.RateLimit.RequestsPerMinute, application.Config.APIServer.RateLimit.Burst),
+	}
+	s.server = &http.Server{
+		Addr:    ":" + application.Config.APIServer.Port,
+		Handler: s.Handler(),
 	}
-	return server
+	return s
 }
 
-func GetServer() *http.Server {
-	if server == nil {
-		server = newServer()
-	}
-	return server
+// Handler returns the routes served by the API server
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/refresh", s.requireToken(s.handleRefresh))
+	mux.HandleFunc("POST /reports/weekly", s.requireToken(s.handleWeeklyReports))
+	mux.HandleFunc("/health", s.handleHealth) // Always open for container health checks
+	mux.HandleFunc("/status", s.requireToken(s.handleStatus))
+
+	// Read API
+	mux.HandleFunc("GET /summoners", s.optionalToken(s.handleSummoners))
+	mux.HandleFunc("GET /champions", s.optionalToken(s.handleChampions))
+	mux.HandleFunc("GET /items", s.optionalToken(s.handleItems))
+	mux.HandleFunc("GET /runes", s.optionalToken(s.handleRunes))
+	mux.HandleFunc("GET /spells", s.optionalToken(s.handleSummonerSpells))
+	mux.HandleFunc("GET /stats/champions", s.optionalToken(s.handleChampionStats))
+	mux.HandleFunc("GET /stats/synergy", s.optionalToken(s.handleSynergy))
+	mux.HandleFunc("GET /stats/matchups", s.optionalToken(s.handleMatchups))
+	mux.HandleFunc("GET /stats/bans", s.optionalToken(s.handleBans))
+	mux.HandleFunc("GET /stats/objectives", s.optionalToken(s.handleObjectives))
+	mux.HandleFunc("GET /stats/tempo", s.optionalToken(s.handleTempo))
+	mux.HandleFunc("GET /stats/sessions", s.optionalToken(s.handleSessions))
+	mux.HandleFunc("GET /goals", s.optionalToken(s.handleGoals))
+	mux.HandleFunc("GET /games", s.optionalToken(s.handleGames))
+	mux.HandleFunc("GET /games/{id}", s.optionalToken(s.handleGame))
+
+	// Images downloaded by "assets sync". The icons are public ddragon images loaded by <img> tags,
+	// which cannot send a token, so they are served like the web UI without auth or rate limiting.
+	mux.Handle("GET "+assets.Prefix+"/", s.app.Assets.Handler())
+	mux.HandleFunc("GET /sprites/{group}/{id}", s.handleSprite)
+	mux.HandleFunc("GET /games/{id}/draft.png", s.optionalToken(s.handleDraft))
+
+	// Web UI, its pages call the read API from the browser
+	mux.Handle("GET /{$}", web.Index())
+	mux.Handle("GET "+web.Prefix+"/", web.Files())
+	return mux
 }
 
-func Start(ctx context.Context) {
-	http.HandleFunc("/refresh", handleRefresh)
-	http.HandleFunc("/health", handleHealth)
+// Start serves the API until ctx is cancelled. In-flight requests and refreshes
+// are then given the configured grace period to finish before they are cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	pidFile := s.app.Config.APIServer.PIDFile
+	if err := writePIDFile(pidFile); err != nil {
+		return err
+	}
+	defer os.Remove(pidFile)
 
-	GetServer() // Initialize the server if necessary
-	server.Handler = http.DefaultServeMux
+	s.startedAt = time.Now()
+	errCh := make(chan error, 1)
 	// Start the server in a goroutine
 	go func() {
-		log.Printf("Starting API server at: %s\n", server.Addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("API server failed to start: %v", err)
+		log.Printf("Starting API server at: %s\n", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- fmt.Errorf("API server failed to start: %w", err)
 		}
 	}()
 
 	// Wait for the context to be cancelled
-	<-ctx.Done()
+	select {
+	case <-ctx.Done():
+	case err := <-errCh:
+		return err
+	}
 
 	// Create a new context with a timeout to allow the server to shut down gracefully
-	ctxShutDown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctxShutDown, cancel := context.WithTimeout(context.Background(), s.app.Config.APIServer.ShutdownGracePeriod)
 	defer cancel()
 
-	if err := server.Shutdown(ctxShutDown); err != nil {
-		log.Fatalf("server shutdown failed:%+v", err)
+	if err := s.Stop(ctxShutDown); err != nil {
+		return fmt.Errorf("server shutdown failed: %w", err)
 	}
 	log.Println("server exited properly")
+	return nil
 }
 
-func Stop(ctx context.Context) error {
+// Stop shuts the HTTP server down and drains the background refresh, cancelling it once ctx is done
+func (s *Server) Stop(ctx context.Context) error {
 	log.Println("Stopping API server...")
-	return server.Shutdown(ctx)
+	err := s.server.Shutdown(ctx)
+	s.refresher.Drain(ctx)
+	return err
 }
 
-func handleRefresh(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Invalid request method. Use POST.", http.StatusMethodNotAllowed)
 		return
 	}
 
-	go func() {
-		if err := client.FetchAndStoreChampionData(); err != nil {
-			log.Printf("Error fetching and storing champion data: %v", err)
-		} else {
-			log.Println("Data fetching and insertion completed successfully")
-		}
-		if err := client.FetchAndStoreGameData(); err != nil {
-			log.Printf("Error fetching and storing game data: %v", err)
-		} else {
-			log.Println("Data fetching and insertion completed successfully")
-		}
-	}()
-
 	response := map[string]string{
 		"status":  "Data refresh initiated",
 		"message": "Your data refresh request is being processed.",
 	}
+	if !s.refresher.Start() {
+		response = map[string]string{
+			"status":  "Data refresh already running",
+			"message": "A data refresh is already in progress.",
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-func handleHealth(w http.ResponseWriter, r *http.Request) {
+// Status is the response of the /status endpoint
+type Status struct {
+	Version   string        `json:"version"`
+	PID       int           `json:"pid"`
+	StartedAt time.Time     `json:"started_at"`
+	Uptime    string        `json:"uptime"`
+	Refresh   RefreshStatus `json:"refresh"`
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method. Use GET.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := Status{
+		Version:   version.Get(),
+		PID:       os.Getpid(),
+		StartedAt: s.startedAt,
+		Uptime:    time.Since(s.startedAt).Round(time.Second).String(),
+		Refresh:   s.refresher.Status(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// writeError logs err and responds with a generic internal server error
+func writeError(w http.ResponseWriter, err error) {
+	log.Printf("Error handling request: %v", err)
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
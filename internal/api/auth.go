@@ -0,0 +1,156 @@
+// internal/api/auth.go
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GenerateToken returns a new random API token and its hex encoded SHA-256 hash
+func GenerateToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hex encoded SHA-256 hash under which a token is stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticate returns the name of the token presented in the Authorization header, or "" if there is no valid token
+func (s *Server) authenticate(r *http.Request) (string, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", nil
+	}
+	hash := HashToken(strings.TrimSpace(token))
+
+	for _, configured := range s.app.Config.APIServer.Auth.Tokens {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(configured.SHA256)) == 1 {
+			return configured.Name, nil
+		}
+	}
+	return s.app.DB.FindAPIToken(r.Context(), hash)
+}
+
+// requireToken rejects requests without a valid token. Used for mutating and admin endpoints.
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return s.withAuth(true, next)
+}
+
+// optionalToken only requires a token when api.auth.protect_reads is set. Used for read endpoints.
+func (s *Server) optionalToken(next http.HandlerFunc) http.HandlerFunc {
+	return s.withAuth(s.app.Config.APIServer.Auth.ProtectReads, next)
+}
+
+func (s *Server) withAuth(required bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := s.authenticate(r)
+		if err != nil {
+			log.Printf("Error authenticating request: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if name == "" && required {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="opggvisualizer"`)
+			http.Error(w, "A valid bearer token is required.", http.StatusUnauthorized)
+			return
+		}
+
+		// Anonymous readers share a bucket per client address
+		key := "token:" + name
+		if name == "" {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+			key = "anonymous:" + host
+		}
+		if wait, ok := s.limiter.Allow(key); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "Rate limit exceeded.", http.StatusTooManyRequests)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// rateLimiter is a token bucket per key
+type rateLimiter struct {
+	rate  float64 // Tokens added per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(requestsPerMinute, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    float64(requestsPerMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of key. If the bucket is empty it returns how long to wait for the next token.
+func (l *rateLimiter) Allow(key string) (time.Duration, bool) {
+	if l.rate <= 0 {
+		return 0, true // Rate limiting disabled
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// sweep drops the buckets that have been idle long enough to refill, as they are no different from a
+// new bucket. It runs at most once per refill period so that Allow stays cheap.
+func (l *rateLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.lastSweep) < refill {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// internal/api/catalogs.go
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"opggvisualizer/internal/config"
+)
+
+// queryLang reads the lang query parameter, the locale of the champion names returned by the read
+// endpoints. Names without a translation in this locale fall back to en_US.
+func queryLang(r *http.Request) string {
+	lang := strings.ReplaceAll(r.URL.Query().Get("lang"), "-", "_") // Accept "de-DE" as well as "de_DE"
+	if lang == "" {
+		return config.DefaultLocale
+	}
+	return lang
+}
+
+// handleChampions lists the champions in the locale given by the lang query parameter
+func (s *Server) handleChampions(w http.ResponseWriter, r *http.Request) {
+	champions, err := s.app.DB.ListChampions(r.Context(), queryLang(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, champions)
+}
+
+func (s *Server) handleItems(w http.ResponseWriter, r *http.Request) {
+	items, err := s.app.DB.ListItems(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, items)
+}
+
+func (s *Server) handleRunes(w http.ResponseWriter, r *http.Request) {
+	runes, err := s.app.DB.ListRunes(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, runes)
+}
+
+func (s *Server) handleSummonerSpells(w http.ResponseWriter, r *http.Request) {
+	spells, err := s.app.DB.ListSummonerSpells(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, spells)
+}
@@ -0,0 +1,66 @@
+// internal/api/games.go
+package api
+
+import (
+	"net/http"
+
+	"opggvisualizer/internal/stats"
+)
+
+// maxGamesLimit caps the limit query parameter of /games
+const maxGamesLimit = 200
+
+// Summoner is a tracked summoner as listed by /summoners
+type Summoner struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Region string `json:"region"`
+}
+
+// handleSummoners lists the configured summoners
+func (s *Server) handleSummoners(w http.ResponseWriter, r *http.Request) {
+	summoners := []Summoner{}
+	for _, summoner := range s.app.Config.Summoners {
+		summoners = append(summoners, Summoner{ID: summoner.ID, Name: summoner.Name, Region: summoner.Region})
+	}
+	writeJSON(w, summoners)
+}
+
+// handleGames lists the latest games of a summoner, newest first
+func (s *Server) handleGames(w http.ResponseWriter, r *http.Request) {
+	filter, err := stats.SummonerFilter(s.app.Config, r.URL.Query().Get("summoner"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r, "limit", 20)
+	if err != nil || limit < 1 || limit > maxGamesLimit {
+		http.Error(w, "Invalid limit, expected 1 to 200", http.StatusBadRequest)
+		return
+	}
+
+	name := filter.SummonerName
+	if name == "" {
+		name = filter.SummonerID
+	}
+	games, err := s.app.DB.ListGames(r.Context(), filter.SummonerID, name, queryLang(r), limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, games)
+}
+
+// handleGame serves the scoreboard of a stored game, shown by the game page of the web UI
+func (s *Server) handleGame(w http.ResponseWriter, r *http.Request) {
+	board, err := s.app.DB.GetScoreboard(r.Context(), r.PathValue("id"), queryLang(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if board == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, board)
+}
@@ -0,0 +1,91 @@
+// internal/api/images.go
+package api
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"log"
+	"net/http"
+	"strings"
+
+	"opggvisualizer/internal/assets"
+)
+
+// handleSprite serves one icon cropped from its sprite sheet, e.g. /sprites/champion/266.png
+func (s *Server) handleSprite(w http.ResponseWriter, r *http.Request) {
+	group := r.PathValue("group")
+	id := strings.TrimSuffix(r.PathValue("id"), ".png")
+
+	sprite, err := s.app.DB.GetSprite(r.Context(), group, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if sprite == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	icon, err := s.app.Assets.Crop(*sprite)
+	if errors.Is(err, assets.ErrNotSynced) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writePNG(w, icon)
+}
+
+// handleDraft serves the champions picked in a game as a 5v5 strip
+func (s *Server) handleDraft(w http.ResponseWriter, r *http.Request) {
+	picks, err := s.app.DB.ListDraftPicks(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(picks) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	var blue, red []image.Image
+	for _, pick := range picks {
+		// A champion without a stored sprite is drawn as an empty tile
+		var icon image.Image
+		sprite, err := s.app.DB.GetSprite(r.Context(), "champion", pick.ChampionID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if sprite != nil {
+			icon, err = s.app.Assets.Crop(*sprite)
+			if errors.Is(err, assets.ErrNotSynced) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+
+		if pick.TeamKey == "BLUE" {
+			blue = append(blue, icon)
+		} else {
+			red = append(red, icon)
+		}
+	}
+	writePNG(w, assets.DraftStrip(blue, red))
+}
+
+// writePNG writes img as a PNG response
+func writePNG(w http.ResponseWriter, img image.Image) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if err := png.Encode(w, img); err != nil {
+		log.Printf("Error encoding image: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+// internal/api/pidfile.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// writePIDFile records the current process id, refusing to overwrite the pidfile of a running server
+func writePIDFile(path string) error {
+	if pid, err := ReadPIDFile(path); err == nil && processAlive(pid) {
+		return fmt.Errorf("API server already running with pid %d (pidfile %s)", pid, path)
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	return nil
+}
+
+// ReadPIDFile returns the process id stored in the pidfile at path
+func ReadPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pidfile: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pidfile %s: %w", path, err)
+	}
+	return pid, nil
+}
+
+// StopProcess asks the server recorded in the pidfile to shut down and waits up to timeout for it to exit
+func StopProcess(pidFile string, timeout time.Duration) error {
+	pid, err := ReadPIDFile(pidFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("API server is not running (no pidfile at %s)", pidFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find process %d: %w", pid, err)
+	}
+	if err := proc.Signal(syscall.SIGTERM); err != nil {
+		if errors.Is(err, os.ErrProcessDone) {
+			os.Remove(pidFile)
+			return fmt.Errorf("API server is not running (stale pidfile removed)")
+		}
+		return fmt.Errorf("failed to signal process %d: %w", pid, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("API server (pid %d) did not exit within %s", pid, timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
+
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
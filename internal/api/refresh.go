@@ -0,0 +1,116 @@
+// internal/api/refresh.go
+package api
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"opggvisualizer/internal/client"
+)
+
+// refresher runs at most one data refresh at a time in the background.
+// Refreshes outlive the request that started them but not the server.
+type refresher struct {
+	client *client.Client
+	ctx    context.Context // Cancelled once the shutdown grace period has passed
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	status RefreshStatus
+}
+
+// RefreshStatus describes the current or most recent refresh
+type RefreshStatus struct {
+	Running    bool      `json:"running"`
+	Stage      string    `json:"stage,omitempty"` // "champions" or "games" while running
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Errors     []string  `json:"errors,omitempty"` // Errors of the last finished refresh
+}
+
+func newRefresher(c *client.Client) *refresher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &refresher{client: c, ctx: ctx, cancel: cancel}
+}
+
+// Start begins a refresh unless one is already running, and reports whether it did
+func (r *refresher) Start() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.status.Running || r.ctx.Err() != nil {
+		return false
+	}
+	r.status = RefreshStatus{Running: true, StartedAt: time.Now()}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		errs := r.run(r.ctx)
+
+		r.mu.Lock()
+		r.status.Running = false
+		r.status.Stage = ""
+		r.status.FinishedAt = time.Now()
+		r.status.Errors = errs
+		r.mu.Unlock()
+	}()
+	return true
+}
+
+// Status returns a snapshot of the current or most recent refresh
+func (r *refresher) Status() RefreshStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+func (r *refresher) setStage(stage string) {
+	r.mu.Lock()
+	r.status.Stage = stage
+	r.mu.Unlock()
+}
+
+func (r *refresher) run(ctx context.Context) []string {
+	var errs []string
+
+	r.setStage("champions")
+	if err := r.client.FetchAndStoreChampionData(ctx); err != nil {
+		log.Printf("Error fetching and storing champion data: %v", err)
+		errs = append(errs, err.Error())
+	} else {
+		log.Println("Data fetching and insertion completed successfully")
+	}
+
+	r.setStage("games")
+	if err := r.client.FetchAndStoreGameData(ctx); err != nil {
+		log.Printf("Error fetching and storing game data: %v", err)
+		errs = append(errs, err.Error())
+	} else {
+		log.Println("Data fetching and insertion completed successfully")
+	}
+	return errs
+}
+
+// Drain waits for a running refresh to finish. If ctx is done first the refresh is cancelled.
+// No refresh can be started afterwards.
+func (r *refresher) Drain(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Println("Grace period expired, cancelling in-flight refresh")
+	}
+
+	r.mu.Lock()
+	r.cancel()
+	r.mu.Unlock()
+	<-done
+}
@@ -0,0 +1,27 @@
+// internal/api/reports.go
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"opggvisualizer/internal/reports"
+	"opggvisualizer/internal/stats"
+)
+
+// handleWeeklyReports publishes the weekly report of every summoner, see reports.Publish. The week
+// defaults to the last full week, ?week=2024-11-04 selects the week containing a date.
+func (s *Server) handleWeeklyReports(w http.ResponseWriter, r *http.Request) {
+	start, err := stats.ParseWeek(r.URL.Query().Get("week"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	published, err := reports.Publish(r.Context(), s.app.Config, s.app.DB, start)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, published)
+}
@@ -0,0 +1,194 @@
+// internal/api/stats.go
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"opggvisualizer/internal/stats"
+)
+
+// statsFilter reads the summoner, since, position and lang query parameters shared by the stats endpoints
+func (s *Server) statsFilter(r *http.Request) (stats.Filter, error) {
+	query := r.URL.Query()
+	filter, err := stats.SummonerFilter(s.app.Config, query.Get("summoner"))
+	if err != nil {
+		return filter, err
+	}
+	filter.Locale = queryLang(r)
+	if filter.Since, err = stats.ParseSince(query.Get("since"), time.Now()); err != nil {
+		return filter, err
+	}
+	if filter.Position, err = stats.ParsePosition(query.Get("position")); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// queryInt reads an integer query parameter, returning fallback when it is not set
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func (s *Server) handleChampionStats(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.statsFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sortKey := r.URL.Query().Get("sort")
+	if sortKey == "" {
+		sortKey = "games"
+	}
+
+	champions, err := stats.Champions(r.Context(), s.app.DB, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := stats.SortChampions(champions, sortKey); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, champions)
+}
+
+func (s *Server) handleSynergy(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.statsFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	minGames, err := queryInt(r, "min_games", 2)
+	if err != nil {
+		http.Error(w, "min_games must be a number", http.StatusBadRequest)
+		return
+	}
+
+	synergy, err := stats.ComputeSynergy(r.Context(), s.app.DB, filter, stats.SynergyOptions{
+		MinGames: minGames,
+		Tracked:  s.app.Config.SummonerIDs(),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, synergy)
+}
+
+func (s *Server) handleMatchups(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.statsFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	minGames, err := queryInt(r, "min_games", 1)
+	if err != nil {
+		http.Error(w, "min_games must be a number", http.StatusBadRequest)
+		return
+	}
+
+	matchups, err := stats.ComputeMatchups(r.Context(), s.app.DB, filter, minGames)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, matchups)
+}
+
+func (s *Server) handleBans(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.statsFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	minGames, err := queryInt(r, "min_games", 2)
+	if err != nil {
+		http.Error(w, "min_games must be a number", http.StatusBadRequest)
+		return
+	}
+
+	bans, err := stats.ComputeBans(r.Context(), s.app.DB, filter, minGames)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, bans)
+}
+
+func (s *Server) handleObjectives(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.statsFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	objectives, err := stats.ComputeObjectives(r.Context(), s.app.DB, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, objectives)
+}
+
+func (s *Server) handleTempo(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.statsFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tempo, err := stats.ComputeTempo(r.Context(), s.app.DB, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, tempo)
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.statsFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	gap := stats.DefaultSessionGap
+	if value := r.URL.Query().Get("gap"); value != "" {
+		if gap, err = time.ParseDuration(value); err != nil || gap <= 0 {
+			http.Error(w, "gap must be a positive duration such as 45m", http.StatusBadRequest)
+			return
+		}
+	}
+
+	sessions, err := stats.ComputeSessions(r.Context(), s.app.DB, filter, gap, time.Local)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, sessions)
+}
+
+// handleGoals serves the goals and their progress, optionally of one summoner
+func (s *Server) handleGoals(w http.ResponseWriter, r *http.Request) {
+	var summonerID string
+	if summoner := r.URL.Query().Get("summoner"); summoner != "" {
+		filter, err := stats.SummonerFilter(s.app.Config, summoner)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		summonerID = filter.SummonerID
+	}
+
+	goals, err := stats.EvaluateGoals(r.Context(), s.app.Config, s.app.DB, summonerID, time.Now())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, goals)
+}
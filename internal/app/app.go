@@ -0,0 +1,40 @@
+// internal/app/app.go
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"opggvisualizer/internal/assets"
+	"opggvisualizer/internal/client"
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/db"
+)
+
+// App holds the dependencies shared by the CLI commands, the fetchers and the API handlers
+type App struct {
+	Config *config.Config
+	DB     *db.Database
+	Client *client.Client
+	Assets *assets.Store
+}
+
+// New opens the database and builds the clients described by cfg
+func New(ctx context.Context, cfg *config.Config) (*App, error) {
+	database, err := db.Open(ctx, cfg.DatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing database: %w", err)
+	}
+
+	return &App{
+		Config: cfg,
+		DB:     database,
+		Client: client.New(cfg, database, client.DefaultEndpoints),
+		Assets: assets.NewStore(cfg.Assets.Dir),
+	}, nil
+}
+
+// Close releases the resources held by the application
+func (a *App) Close() error {
+	return a.DB.Close()
+}
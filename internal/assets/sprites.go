@@ -0,0 +1,107 @@
+// internal/assets/sprites.go
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+
+	"opggvisualizer/internal/models"
+)
+
+// ErrNotSynced is returned when a sprite sheet has not been downloaded by "assets sync"
+var ErrNotSynced = errors.New("sprite sheet has not been synced, run assets sync")
+
+// Draft strip layout, in pixels. ddragon icons are 48x48.
+const (
+	tileSize  = 48
+	teamGap   = 16
+	teamBar   = 4
+	teamSize  = 5
+	stripSize = 2*teamSize*tileSize + teamGap
+)
+
+var (
+	blueTeam   = color.RGBA{0x1f, 0x6f, 0xd1, 0xff}
+	redTeam    = color.RGBA{0xd1, 0x3b, 0x3b, 0xff}
+	background = color.RGBA{0x18, 0x1b, 0x1f, 0xff}
+)
+
+// Crop returns the part of a stored sprite sheet showing one image
+func (s *Store) Crop(sprite models.Sprite) (image.Image, error) {
+	sheet, err := s.sheet(sprite.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := image.Rect(sprite.X, sprite.Y, sprite.X+sprite.W, sprite.Y+sprite.H).Add(sheet.Bounds().Min)
+	if !bounds.In(sheet.Bounds()) {
+		return nil, fmt.Errorf("sprite %v lies outside of sheet %s", bounds, sprite.URL)
+	}
+
+	// Copy the icon so the whole sheet is not encoded along with it
+	icon := image.NewRGBA(image.Rect(0, 0, sprite.W, sprite.H))
+	draw.Draw(icon, icon.Bounds(), sheet, bounds.Min, draw.Src)
+	return icon, nil
+}
+
+// sheet decodes a stored sprite sheet. Decoded sheets are cached, they never change for a version.
+func (s *Store) sheet(spriteURL string) (image.Image, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sheet, ok := s.sheets[spriteURL]; ok {
+		return sheet, nil
+	}
+
+	p, err := s.Path(spriteURL)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotSynced
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sprite sheet: %w", err)
+	}
+	defer f.Close()
+
+	sheet, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sprite sheet %s: %w", p, err)
+	}
+	s.sheets[spriteURL] = sheet
+	return sheet, nil
+}
+
+// DraftStrip draws the champions of both teams in one row, blue on the left and red on the right,
+// each above a bar in the team colour. Missing icons are left as empty tiles.
+func DraftStrip(blue, red []image.Image) *image.RGBA {
+	strip := image.NewRGBA(image.Rect(0, 0, stripSize, tileSize+teamBar))
+	draw.Draw(strip, strip.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	drawTeam(strip, blue, 0, blueTeam)
+	drawTeam(strip, red, teamSize*tileSize+teamGap, redTeam)
+	return strip
+}
+
+func drawTeam(strip *image.RGBA, icons []image.Image, offset int, teamColor color.Color) {
+	bar := image.Rect(offset, tileSize, offset+teamSize*tileSize, tileSize+teamBar)
+	draw.Draw(strip, bar, image.NewUniform(teamColor), image.Point{}, draw.Src)
+
+	for i, icon := range icons {
+		if i == teamSize {
+			break
+		}
+		if icon == nil {
+			continue
+		}
+		tile := image.Rect(offset+i*tileSize, 0, offset+(i+1)*tileSize, tileSize)
+		draw.Draw(strip, tile, icon, icon.Bounds().Min, draw.Over)
+	}
+}
@@ -0,0 +1,104 @@
+// internal/assets/store.go
+package assets
+
+import (
+	"fmt"
+	"image"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Prefix is the path the API server serves the store under
+const Prefix = "/assets"
+
+// Store keeps a local copy of ddragon images. Files are laid out like the ddragon CDN, so the
+// image at https://ddragon.leagueoflegends.com/cdn/14.24.1/img/champion/Ahri.png is stored as
+// cdn/14.24.1/img/champion/Ahri.png and served as /assets/cdn/14.24.1/img/champion/Ahri.png.
+type Store struct {
+	Dir string
+
+	mu     sync.Mutex
+	sheets map[string]image.Image // Decoded sprite sheets by URL
+}
+
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir, sheets: make(map[string]image.Image)}
+}
+
+// RelativePath returns the CDN path of an image URL, e.g. "cdn/14.24.1/img/champion/Ahri.png"
+func RelativePath(imageURL string) (string, error) {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image URL %q: %w", imageURL, err)
+	}
+	cleaned := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	if cleaned == "" {
+		return "", fmt.Errorf("image URL %q has no path", imageURL)
+	}
+	return cleaned, nil
+}
+
+// Path returns the location of the local copy of an image
+func (s *Store) Path(imageURL string) (string, error) {
+	relative, err := RelativePath(imageURL)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(relative)), nil
+}
+
+// Has reports whether the image has been downloaded
+func (s *Store) Has(imageURL string) bool {
+	p, err := s.Path(imageURL)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(p)
+	return err == nil
+}
+
+// Write stores the image. The file is written under a temporary name first, so a
+// server reading the store never sees a partial image.
+func (s *Store) Write(imageURL string, data []byte) error {
+	p, err := s.Path(imageURL)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create asset directory: %w", err)
+	}
+
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write asset: %w", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to move asset into place: %w", err)
+	}
+	return nil
+}
+
+// Handler serves the stored images under Prefix. Directory listings are not served. Stored images are
+// cached by browsers, the path of an image changes with the ddragon version.
+func (s *Store) Handler() http.Handler {
+	dir := http.Dir(s.Dir)
+	files := http.StripPrefix(Prefix, http.FileServer(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		// Images missing until the next "assets sync" are not cached
+		if f, err := dir.Open(strings.TrimPrefix(r.URL.Path, Prefix)); err == nil {
+			f.Close()
+			w.Header().Set("Cache-Control", "public, max-age=86400")
+		}
+		files.ServeHTTP(w, r)
+	})
+}
@@ -3,29 +3,101 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"opggvisualizer/internal/api"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
-func newStartAPICmd(ctx context.Context) *cobra.Command {
+func newStartAPICmd(ctx context.Context, rt *runtime) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "server start",
+		Use:   "start",
 		Short: "Start the API server",
-		Run: func(cmd *cobra.Command, args []string) {
-			api.Start(ctx)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return api.NewServer(rt.app).Start(ctx)
 		},
 	}
 	return cmd
 }
 
-func newStopAPICmd(ctx context.Context) *cobra.Command {
+func newStopAPICmd(rt *runtime) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "server stop",
-		Short: "Stop the API server",
-		Run: func(cmd *cobra.Command, args []string) {
-			api.Stop(ctx)
+		Use:   "stop",
+		Short: "Stop the API server running on this machine",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := rt.app.Config.APIServer
+			// Leave the server its full grace period plus a little time to exit
+			if err := api.StopProcess(cfg.PIDFile, cfg.ShutdownGracePeriod+5*time.Second); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "API server stopped")
+			return nil
 		},
 	}
 	return cmd
 }
+
+func newStatusAPICmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var url, token string
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Report the uptime, version and refresh job of the API server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if url == "" {
+				url = "http://localhost:" + rt.app.Config.APIServer.Port
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/status", nil)
+			if err != nil {
+				return err
+			}
+			if token == "" {
+				token = os.Getenv("API_TOKEN")
+			}
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			client := &http.Client{Timeout: 5 * time.Second}
+			resp, err := client.Do(req)
+			if err != nil {
+				return fmt.Errorf("API server is not reachable at %s: %w", url, err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode == http.StatusUnauthorized {
+				return fmt.Errorf("the API server requires a valid token, set --token or API_TOKEN")
+			}
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("unexpected status from API server: %s", resp.Status)
+			}
+
+			var status api.Status
+			if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+				return fmt.Errorf("invalid status response: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Version:  %s\n", status.Version)
+			fmt.Fprintf(cmd.OutOrStdout(), "PID:      %d\n", status.PID)
+			fmt.Fprintf(cmd.OutOrStdout(), "Started:  %s\n", status.StartedAt.Format(time.RFC3339))
+			fmt.Fprintf(cmd.OutOrStdout(), "Uptime:   %s\n", status.Uptime)
+			switch {
+			case status.Refresh.Running:
+				fmt.Fprintf(cmd.OutOrStdout(), "Refresh:  running %s since %s\n", status.Refresh.Stage, status.Refresh.StartedAt.Format(time.RFC3339))
+			case status.Refresh.FinishedAt.IsZero():
+				fmt.Fprintln(cmd.OutOrStdout(), "Refresh:  none since start")
+			default:
+				fmt.Fprintf(cmd.OutOrStdout(), "Refresh:  last finished %s with %d error(s)\n", status.Refresh.FinishedAt.Format(time.RFC3339), len(status.Refresh.Errors))
+				for _, e := range status.Refresh.Errors {
+					fmt.Fprintf(cmd.OutOrStdout(), "          %s\n", e)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&url, "url", "", "Base URL of the API server (default http://localhost:<api port>)")
+	cmd.Flags().StringVar(&token, "token", "", "API token sent to the server (default $API_TOKEN)")
+	return cmd
+}
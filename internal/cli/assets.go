@@ -0,0 +1,38 @@
+// internal/cli/assets.go
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newAssetsCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "assets",
+		Short: "Manage the local copy of ddragon images",
+	}
+	cmd.AddCommand(newAssetsSyncCmd(ctx, rt))
+	return cmd
+}
+
+func newAssetsSyncCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Download the champion, item, rune and summoner spell images of the current version",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := rt.app.Client.SyncAssets(ctx, rt.app.Assets)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Downloaded %d, already stored %d, failed %d. Assets are stored in %s\n",
+				result.Downloaded, result.Existing, result.Failed, rt.app.Assets.Dir)
+			if result.Failed > 0 {
+				return fmt.Errorf("%d assets failed to download, run assets sync again to retry", result.Failed)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
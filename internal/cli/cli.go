@@ -4,22 +4,107 @@ package cli
 import (
 	"context"
 
+	"opggvisualizer/internal/app"
+	"opggvisualizer/internal/config"
+
 	"github.com/spf13/cobra"
 )
 
-func NewRootCommand(ctx context.Context) *cobra.Command {
+// AppFactory builds the application container from the loaded configuration
+type AppFactory func(ctx context.Context, cfg *config.Config) (*app.App, error)
+
+// globalFlags are the persistent flags shared by every command
+type globalFlags struct {
+	configPath string
+	overrides  config.Overrides
+}
+
+// runtime is shared by all commands. The application is built once the flags have been parsed.
+type runtime struct {
+	flags  globalFlags
+	newApp AppFactory
+	app    *app.App
+}
+
+func NewRootCommand(ctx context.Context, newApp AppFactory) *cobra.Command {
+	rt := &runtime{newApp: newApp}
+
 	rootCmd := &cobra.Command{
-		Use:   "opggvisualizer",
-		Short: "A tool to visualize League of Legends game data",
+		Use:           "opggvisualizer",
+		Short:         "A tool to visualize League of Legends game data",
+		SilenceUsage:  true,
+		SilenceErrors: true, // main logs the returned error
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(rt.flags.configPath, rt.flags.overrides)
+			if err != nil {
+				return err
+			}
+			rt.app, err = rt.newApp(ctx, cfg)
+			return err
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			if rt.app == nil {
+				return nil
+			}
+			return rt.app.Close()
+		},
 	}
 
+	rootCmd.PersistentFlags().StringVar(&rt.flags.configPath, "config", "", "Path to the config file (default $CONFIG_PATH or "+config.DefaultConfigPath+")")
+	rootCmd.PersistentFlags().StringVar(&rt.flags.overrides.SummonerID, "summoner-id", "", "op.gg summoner id to track, replaces the configured summoners")
+	rootCmd.PersistentFlags().StringVar(&rt.flags.overrides.Region, "region", "", "Default op.gg region")
+	rootCmd.PersistentFlags().StringVar(&rt.flags.overrides.DatabasePath, "database-path", "", "Path to the SQLite database")
+	rootCmd.PersistentFlags().StringVar(&rt.flags.overrides.APIPort, "api-port", "", "Port the API server listens on")
+
 	// Add subcommands
-	rootCmd.AddCommand(newFetchChampionsCommand()) // TODO: Add context to other commands
-	rootCmd.AddCommand(newFetchGamesCommand())
-	rootCmd.AddCommand(newStartAPICmd(ctx))
-	rootCmd.AddCommand(newStopAPICmd(ctx))
-	rootCmd.AddCommand(newDBClearChampionsCmd())
-	rootCmd.AddCommand(newDBClearGamesCmd())
+	rootCmd.AddCommand(newChampionsCmd(ctx, rt))
+	rootCmd.AddCommand(newGamesCmd(ctx, rt))
+	rootCmd.AddCommand(newServerCmd(ctx, rt))
+	rootCmd.AddCommand(newTokensCmd(ctx, rt))
+	rootCmd.AddCommand(newAssetsCmd(ctx, rt))
+	rootCmd.AddCommand(newStatsCmd(ctx, rt))
+	rootCmd.AddCommand(newGoalsCmd(ctx, rt))
+	rootCmd.AddCommand(newWebhooksCmd(ctx, rt))
+	rootCmd.AddCommand(newReportCmd(ctx, rt))
+	rootCmd.AddCommand(newGrafanaCmd(rt))
+	rootCmd.AddCommand(newConfigCmd(rt))
 
 	return rootCmd
 }
+
+func newChampionsCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "champions",
+		Short: "Manage champion data",
+	}
+	cmd.AddCommand(newFetchChampionsCommand(ctx, rt))
+	cmd.AddCommand(newBackfillChampionsCommand(ctx, rt))
+	cmd.AddCommand(newDBClearChampionsCmd(ctx, rt))
+	cmd.AddCommand(newDiffChampionsCmd(ctx, rt))
+	return cmd
+}
+
+func newGamesCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "games",
+		Short: "Manage game data",
+	}
+	cmd.AddCommand(newFetchGamesCommand(ctx, rt))
+	cmd.AddCommand(newListGamesCmd(ctx, rt))
+	cmd.AddCommand(newShowGameCmd(ctx, rt))
+	cmd.AddCommand(newRecomputeMetricsCmd(ctx, rt))
+	cmd.AddCommand(newClassifyGamesCmd(ctx, rt))
+	cmd.AddCommand(newDBClearGamesCmd(ctx, rt))
+	return cmd
+}
+
+func newServerCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "server",
+		Short: "Manage the API server",
+	}
+	cmd.AddCommand(newStartAPICmd(ctx, rt))
+	cmd.AddCommand(newStopAPICmd(rt))
+	cmd.AddCommand(newStatusAPICmd(ctx, rt))
+	return cmd
+}
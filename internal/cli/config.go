@@ -0,0 +1,38 @@
+// internal/cli/config.go
+package cli
+
+import (
+	"fmt"
+	"opggvisualizer/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd(rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+		// The subcommands load the configuration themselves and never build the application
+		PersistentPreRunE:  func(cmd *cobra.Command, args []string) error { return nil },
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	cmd.AddCommand(newConfigValidateCmd(rt))
+	return cmd
+}
+
+func newConfigValidateCmd(rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the config file, environment and flags",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(rt.flags.configPath, rt.flags.overrides)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Configuration is valid: %d summoner(s), database %s, API port %s\n",
+				len(cfg.Summoners), cfg.DatabasePath, cfg.APIServer.Port)
+			return nil
+		},
+	}
+	return cmd
+}
@@ -2,18 +2,17 @@
 package cli
 
 import (
-	"opggvisualizer/internal/db"
+	"context"
 
 	"github.com/spf13/cobra"
 )
 
-func newDBClearChampionsCmd() *cobra.Command {
+func newDBClearChampionsCmd(ctx context.Context, rt *runtime) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "champions wipe",
+		Use:   "wipe",
 		Short: "Removes all champion data from the database",
 		Run: func(cmd *cobra.Command, args []string) {
-			database := db.GetDatabaseConnection()
-			err := database.ClearChampionData()
+			err := rt.app.DB.ClearChampionData(ctx)
 			if err != nil {
 				cmd.PrintErrf("Error clearing champion data: %v\n", err)
 				return
@@ -23,13 +22,12 @@ func newDBClearChampionsCmd() *cobra.Command {
 	return cmd
 }
 
-func newDBClearGamesCmd() *cobra.Command {
+func newDBClearGamesCmd(ctx context.Context, rt *runtime) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "games wipe",
+		Use:   "wipe",
 		Short: "Removes all Game and Participant data from the database",
 		Run: func(cmd *cobra.Command, args []string) {
-			database := db.GetDatabaseConnection()
-			err := database.ClearGameData()
+			err := rt.app.DB.ClearGameData(ctx)
 			if err != nil {
 				cmd.PrintErrf("Error clearing game data: %v\n", err)
 				return
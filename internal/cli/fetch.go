@@ -2,33 +2,54 @@
 package cli
 
 import (
+	"context"
 	"log"
 
-	"opggvisualizer/internal/client"
-
 	"github.com/spf13/cobra"
 )
 
-func newFetchChampionsCommand() *cobra.Command {
+func newFetchChampionsCommand(ctx context.Context, rt *runtime) *cobra.Command {
+	var version string
 	cmd := &cobra.Command{
-		Use:   "champions fetch",
+		Use:   "fetch",
 		Short: "Fetch and store champion data",
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := client.FetchAndStoreChampionData(); err != nil {
+			var err error
+			if version != "" {
+				err = rt.app.Client.FetchAndStoreStaticData(ctx, version)
+			} else {
+				err = rt.app.Client.FetchAndStoreChampionData(ctx)
+			}
+			if err != nil {
 				log.Fatalf("Error fetching and storing champion data: %v", err)
 			}
 			log.Println("Data fetching and insertion completed successfully.")
 		},
 	}
+	cmd.Flags().StringVar(&version, "version", "", "Fetch a specific ddragon version, e.g. 14.23.1, into the version history")
+	return cmd
+}
+
+func newBackfillChampionsCommand(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Fetch champion and item data for every patch a stored game was played on",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rt.app.Client.FetchAndStoreMissingStaticData(ctx); err != nil {
+				log.Fatalf("Error backfilling champion data: %v", err)
+			}
+			log.Println("Data fetching and insertion completed successfully.")
+		},
+	}
 	return cmd
 }
 
-func newFetchGamesCommand() *cobra.Command {
+func newFetchGamesCommand(ctx context.Context, rt *runtime) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "games fetch",
+		Use:   "fetch",
 		Short: "Fetch and store game data",
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := client.FetchAndStoreGameData(); err != nil {
+			if err := rt.app.Client.FetchAndStoreGameData(ctx); err != nil {
 				log.Fatalf("Error fetching and storing game data: %v", err)
 			}
 			log.Println("Data fetching and insertion completed successfully.")
@@ -0,0 +1,196 @@
+// internal/cli/games.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/models"
+	"opggvisualizer/internal/stats"
+
+	"github.com/spf13/cobra"
+)
+
+func newListGamesCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var summoner string
+	var limit int
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the latest stored games of a summoner",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := stats.SummonerFilter(rt.app.Config, summoner)
+			if err != nil {
+				return err
+			}
+			name := filter.SummonerName
+			if name == "" {
+				name = filter.SummonerID
+			}
+
+			games, err := rt.app.DB.ListGames(ctx, filter.SummonerID, name, config.DefaultLocale, limit)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(cmd, games)
+			}
+			if len(games) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No games found for %s\n", filter.SummonerID)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "GAME\tPLAYED\tLENGTH\tPATCH\tRESULT\tCHAMPION\tPOSITION\tK/D/A\tFLAGS")
+			for _, game := range games {
+				result := game.Result
+				if game.IsRemake {
+					result = "REMAKE"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d/%d/%d\t%s\n",
+					game.GameID, game.CreatedAt.Local().Format("2006-01-02 15:04"), formatGameLength(game.GameLength),
+					game.Patch, result, game.Champion, game.Position, game.Kills, game.Deaths, game.Assists, joinOrDash(game.Flags))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&summoner, "summoner", "", "Configured summoner id or name, required when several summoners are configured")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Number of games to list")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the games as JSON")
+	return cmd
+}
+
+func newShowGameCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "show <game_id>",
+		Short: "Print the scoreboard of a stored game",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			board, err := rt.app.DB.GetScoreboard(ctx, args[0], config.DefaultLocale)
+			if err != nil {
+				return err
+			}
+			if board == nil {
+				return fmt.Errorf("game %s is not stored, list games with: games list", args[0])
+			}
+			if asJSON {
+				return printJSON(cmd, board)
+			}
+			return printScoreboard(cmd.OutOrStdout(), board)
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the scoreboard as JSON")
+	return cmd
+}
+
+func newRecomputeMetricsCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Recompute the derived metrics of every stored participant",
+		Long: "Recompute KDA, kill participation, damage and gold share, CS and vision per minute, damage per gold\n" +
+			"and OP score of every stored participant. Metrics are computed when games are fetched, this is only\n" +
+			"needed after their formulas change.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			count, err := rt.app.DB.RecomputeParticipantMetrics(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Recomputed the metrics of %d participants\n", count)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newClassifyGamesCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "classify",
+		Short: "Flag remakes, early surrenders, suspected leavers and incomplete data in every stored game",
+		Long: "Flag remakes, early surrenders, suspected leavers and incomplete data in every stored game.\n" +
+			"Games are flagged when they are fetched, this is only needed after the classifier changes.\n" +
+			"The stats leave out the games with the flags in the stats.exclude setting.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			counts, err := rt.app.DB.ClassifyGames(ctx)
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "FLAG\tGAMES\tEXCLUDED")
+			for _, flag := range models.GameFlags {
+				excluded := ""
+				if slices.Contains(rt.app.Config.Stats.Exclude, flag) {
+					excluded = "yes"
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\n", flag, counts[flag], excluded)
+			}
+			return w.Flush()
+		},
+	}
+	return cmd
+}
+
+func printScoreboard(out io.Writer, board *models.Scoreboard) error {
+	fmt.Fprintf(out, "Game %s  %s  %s  patch %s", board.GameID,
+		board.CreatedAt.Local().Format("2006-01-02 15:04"), formatGameLength(board.GameLength), board.Patch)
+	if len(board.Flags) > 0 {
+		fmt.Fprintf(out, "  flagged: %s", strings.Join(board.Flags, ", "))
+	}
+	fmt.Fprintln(out)
+
+	for _, team := range board.Teams {
+		result := "LOSS"
+		if team.IsWin {
+			result = "WIN"
+		}
+		fmt.Fprintf(out, "\n%s  %s  %d/%d/%d  %.1fk gold\n", team.Key, result, team.Kills, team.Deaths, team.Assists, float64(team.GoldEarned)/1000)
+		fmt.Fprintf(out, "  Objectives: dragons %d, barons %d, heralds %d, grubs %d, towers %d, inhibitors %d\n",
+			team.Dragons, team.Barons, team.Heralds, team.Hordes, team.Towers, team.Inhibitors)
+		fmt.Fprintf(out, "  Firsts:     %s\n", joinOrDash(team.Firsts))
+		fmt.Fprintf(out, "  Bans:       %s\n\n", joinNamed(team.Bans))
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  SUMMONER\tCHAMPION\tPOS\tK/D/A\tCS\tGOLD\tDAMAGE\tTAKEN\tVISION\tOP RANK\tSPELLS\tRUNES\tITEMS")
+		for _, p := range team.Players {
+			cs := "-"
+			if p.CS != nil {
+				cs = fmt.Sprint(*p.CS)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d/%d/%d\t%s\t%d\t%d\t%d\t%d\t%d\t%s\t%s / %s\t%s\n",
+				p.SummonerName, p.Champion, p.Position, p.Kills, p.Deaths, p.Assists, cs,
+				p.GoldEarned, p.DamageDealt, p.DamageTaken, p.VisionScore, p.OPScoreRank,
+				joinNamed(p.Spells), p.PrimaryRune, p.SecondaryTree, joinNamed(p.Items))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatGameLength formats a game length in seconds as minutes and seconds, e.g. "31:05"
+func formatGameLength(seconds int) string {
+	d := time.Duration(seconds) * time.Second
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), seconds%60)
+}
+
+func joinNamed(named []models.NamedID) string {
+	names := make([]string, len(named))
+	for i, n := range named {
+		names[i] = n.String()
+	}
+	return joinOrDash(names)
+}
+
+func joinOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ", ")
+}
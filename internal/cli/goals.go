@@ -0,0 +1,154 @@
+// internal/cli/goals.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"opggvisualizer/internal/models"
+	"opggvisualizer/internal/stats"
+
+	"github.com/spf13/cobra"
+)
+
+func newGoalsCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "goals",
+		Short: "Manage goals, evaluated after each games fetch",
+	}
+	cmd.AddCommand(newGoalsAddCmd(ctx, rt))
+	cmd.AddCommand(newGoalsRemoveCmd(ctx, rt))
+	cmd.AddCommand(newGoalsStatusCmd(ctx, rt))
+	return cmd
+}
+
+func newGoalsAddCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var summoner, position string
+	var goal models.Goal
+	var sharePercent float64
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a goal for a tracked summoner",
+		Example: "  opggvisualizer goals add --metric vision_score --target 30 --share 70 --period week\n" +
+			"  opggvisualizer goals add --metric deaths --comparison '<' --target 5 --position jungle --period all",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := stats.SummonerFilter(rt.app.Config, summoner)
+			if err != nil {
+				return err
+			}
+			goal.SummonerID = filter.SummonerID
+			if goal.Position, err = stats.ParsePosition(position); err != nil {
+				return err
+			}
+			goal.Share = sharePercent / 100
+			goal.CreatedAt = time.Now()
+			if err := stats.ValidateGoal(goal); err != nil {
+				return err
+			}
+
+			if goal.ID, err = rt.app.DB.InsertGoal(ctx, goal); err != nil {
+				return err
+			}
+			progress, err := stats.EvaluateGoal(ctx, rt.app.Config, rt.app.DB, goal, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := rt.app.DB.UpdateGoalProgress(ctx, progress); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added goal %d: %s, currently %s\n", goal.ID, goal, formatGoalProgress(progress))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&summoner, "summoner", "", "Configured summoner id or name, required when several summoners are configured")
+	cmd.Flags().StringVar(&goal.Metric, "metric", "", "Metric to track: "+strings.Join(stats.GoalMetrics, ", "))
+	cmd.Flags().StringVar(&goal.Comparison, "comparison", ">=", "Comparison with the target: "+strings.Join(stats.GoalComparisons, " "))
+	cmd.Flags().Float64Var(&goal.Target, "target", 0, "Target value of the metric")
+	cmd.Flags().Float64Var(&sharePercent, "share", 0, "Percentage of games that must meet the target, 0 compares the average instead")
+	cmd.Flags().StringVar(&position, "position", "", "Only count games in this position")
+	cmd.Flags().StringVar(&goal.Period, "period", models.PeriodWeek, "Games counted: "+strings.Join(stats.GoalPeriods, ", "))
+	cmd.MarkFlagRequired("metric")
+	cmd.MarkFlagRequired("target")
+	return cmd
+}
+
+func newGoalsRemoveCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <id>",
+		Short: "Remove a goal",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid goal id %q", args[0])
+			}
+			if err := rt.app.DB.DeleteGoal(ctx, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed goal %d\n", id)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newGoalsStatusCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var summoner string
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Print the progress of every goal",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var summonerID string
+			if summoner != "" {
+				filter, err := stats.SummonerFilter(rt.app.Config, summoner)
+				if err != nil {
+					return err
+				}
+				summonerID = filter.SummonerID
+			}
+
+			goals, err := stats.EvaluateGoals(ctx, rt.app.Config, rt.app.DB, summonerID, time.Now())
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(cmd, goals)
+			}
+			if len(goals) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No goals set, add one with: goals add")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tSUMMONER\tGOAL\tGAMES\tPROGRESS\tMET\tEVALUATED")
+			for _, p := range goals {
+				met, evaluated := "no", "never"
+				if p.Met {
+					met = "yes"
+				}
+				if p.EvaluatedAt != nil {
+					evaluated = p.EvaluatedAt.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\t%s\n", p.Goal.ID, p.Goal.SummonerID, p.Description, p.Games,
+					formatGoalProgress(p), met, evaluated)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&summoner, "summoner", "", "Only print the goals of this summoner")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the goals as JSON")
+	return cmd
+}
+
+// formatGoalProgress formats the measured value of a goal, e.g. "58% of 12 games" or "4.20 over 12 games"
+func formatGoalProgress(p models.GoalProgress) string {
+	if p.Goal.Share > 0 {
+		return fmt.Sprintf("%.0f%% of %d games", p.Value*100, p.Games)
+	}
+	return fmt.Sprintf("%.2f over %d games", p.Value, p.Games)
+}
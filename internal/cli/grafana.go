@@ -0,0 +1,88 @@
+// internal/cli/grafana.go
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/grafana"
+
+	"github.com/spf13/cobra"
+)
+
+func newGrafanaCmd(rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grafana",
+		Short: "Manage the Grafana dashboard",
+		// The dashboard is built from code, the subcommands only load the stats.exclude setting and never
+		// build the application
+		PersistentPreRunE:  func(cmd *cobra.Command, args []string) error { return nil },
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	cmd.AddCommand(newGrafanaGenerateCmd(rt))
+	return cmd
+}
+
+func newGrafanaGenerateCmd(rt *runtime) *cobra.Command {
+	var out string
+	var check bool
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate the Grafana dashboard from the panel specs in internal/grafana",
+		Example: "  opggvisualizer grafana generate\n" +
+			"  opggvisualizer grafana generate --check\n" +
+			"  opggvisualizer grafana generate --out -",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(rt.flags.configPath, rt.flags.overrides)
+			if err != nil {
+				return err
+			}
+			exclude := cfg.Stats.Exclude
+
+			if check {
+				provisioned, err := os.ReadFile(out)
+				if err != nil {
+					return fmt.Errorf("failed to read dashboard: %w", err)
+				}
+				diffs, err := grafana.Drift(provisioned, exclude)
+				if err != nil {
+					return err
+				}
+				if len(diffs) > 0 {
+					return fmt.Errorf("%s is out of date, run: opggvisualizer grafana generate\n  %s", out, strings.Join(diffs, "\n  "))
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s is up to date\n", out)
+				return nil
+			}
+
+			dashboard, err := grafana.Generate(exclude)
+			if err != nil {
+				return err
+			}
+			if out == "-" {
+				_, err := cmd.OutOrStdout().Write(dashboard)
+				return err
+			}
+			current, err := os.ReadFile(out)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to read dashboard: %w", err)
+			}
+			if bytes.Equal(current, dashboard) {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s is up to date\n", out)
+				return nil
+			}
+			if err := os.WriteFile(out, dashboard, 0o644); err != nil {
+				return fmt.Errorf("failed to write dashboard: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", grafana.DashboardPath, "Dashboard file, - prints the dashboard")
+	cmd.Flags().BoolVar(&check, "check", false, "Fail when the dashboard file differs from the generated dashboard instead of writing it")
+	return cmd
+}
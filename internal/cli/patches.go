@@ -0,0 +1,37 @@
+// internal/cli/patches.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"opggvisualizer/internal/patches"
+
+	"github.com/spf13/cobra"
+)
+
+func newDiffChampionsCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "diff <from> <to>",
+		Short: "Show champion and item changes between two patches or ddragon versions",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			diff, err := patches.Compare(ctx, rt.app.DB, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(diff)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), diff.String())
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the diff as JSON")
+	return cmd
+}
@@ -0,0 +1,120 @@
+// internal/cli/report.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/reports"
+	"opggvisualizer/internal/stats"
+
+	"github.com/spf13/cobra"
+)
+
+func newReportCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate reports from the stored games",
+	}
+	cmd.AddCommand(newReportWeeklyCmd(ctx, rt))
+	cmd.AddCommand(newReportMailboxCmd(ctx))
+	return cmd
+}
+
+func newReportWeeklyCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var summoner, week, format, out string
+	var publish, asJSON bool
+	cmd := &cobra.Command{
+		Use:   "weekly",
+		Short: "Summarize the games of a week and compare them with the week before",
+		Example: "  opggvisualizer report weekly --format markdown\n" +
+			"  opggvisualizer report weekly --week 2024-11-04 --out report.html\n" +
+			"  opggvisualizer report weekly --publish",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := rt.app.Config
+			start, err := stats.ParseWeek(week, time.Now())
+			if err != nil {
+				return err
+			}
+
+			if publish {
+				published, err := reports.Publish(ctx, cfg, rt.app.DB, start)
+				for _, p := range published {
+					fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s", p.Path)
+					if len(p.EmailedTo) > 0 {
+						fmt.Fprintf(cmd.OutOrStdout(), ", emailed to %s", strings.Join(p.EmailedTo, ", "))
+					}
+					fmt.Fprintln(cmd.OutOrStdout())
+				}
+				return err
+			}
+
+			report, err := reports.Weekly(ctx, cfg, rt.app.DB, summoner, start)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(cmd, report)
+			}
+			if format == "" {
+				format = cfg.Reports.Format
+			}
+			if out == "" {
+				return reports.Render(cmd.OutOrStdout(), format, report)
+			}
+			f, err := os.Create(out)
+			if err != nil {
+				return fmt.Errorf("failed to create report file: %w", err)
+			}
+			defer f.Close()
+			if err := reports.Render(f, format, report); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", out)
+			return f.Close()
+		},
+	}
+	cmd.Flags().StringVar(&summoner, "summoner", "", "Configured summoner id or name, required when several summoners are configured")
+	cmd.Flags().StringVar(&week, "week", "", "A date in the week to report, e.g. 2024-11-04 (default the last full week)")
+	cmd.Flags().StringVar(&format, "format", "", "Report format: "+strings.Join(config.ReportFormats, ", ")+" (default reports.format)")
+	cmd.Flags().StringVar(&out, "out", "", "Write the report to this file instead of stdout")
+	cmd.Flags().BoolVar(&publish, "publish", false, "Write the report of every summoner to reports.dir and email it when reports.email is configured")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the report data as JSON")
+	return cmd
+}
+
+func newReportMailboxCmd(ctx context.Context) *cobra.Command {
+	var port int
+	cmd := &cobra.Command{
+		Use:   "mailbox",
+		Short: "Run a local stand-in SMTP server that prints every report email",
+		Example: "  opggvisualizer report mailbox --port 1025\n" +
+			"  SMTP_ADDR=localhost:1025 opggvisualizer report weekly --publish",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+			if err != nil {
+				return fmt.Errorf("failed to listen: %w", err)
+			}
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- reports.NewMailbox(cmd.OutOrStdout()).Serve(listener)
+			}()
+			fmt.Fprintf(cmd.OutOrStdout(), "Receiving emails at localhost:%d\n", port)
+
+			select {
+			case err := <-errCh:
+				return fmt.Errorf("mailbox failed: %w", err)
+			case <-ctx.Done():
+			}
+			return listener.Close()
+		},
+	}
+	cmd.Flags().IntVar(&port, "port", 1025, "Port to listen on")
+	return cmd
+}
@@ -0,0 +1,457 @@
+// internal/cli/stats.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/stats"
+
+	"github.com/spf13/cobra"
+)
+
+func newStatsCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Print statistics of the stored games",
+	}
+	cmd.AddCommand(newStatsChampionsCmd(ctx, rt))
+	cmd.AddCommand(newStatsSynergyCmd(ctx, rt))
+	cmd.AddCommand(newStatsMatchupsCmd(ctx, rt))
+	cmd.AddCommand(newStatsBansCmd(ctx, rt))
+	cmd.AddCommand(newStatsObjectivesCmd(ctx, rt))
+	cmd.AddCommand(newStatsTempoCmd(ctx, rt))
+	cmd.AddCommand(newStatsSessionsCmd(ctx, rt))
+	return cmd
+}
+
+// statsFlags are shared by the stats commands
+type statsFlags struct {
+	summoner string
+	since    string
+	position string
+	asJSON   bool
+}
+
+func (f *statsFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.summoner, "summoner", "", "Configured summoner id or name, required when several summoners are configured")
+	cmd.Flags().StringVar(&f.since, "since", "", "Only count games since a date (2024-11-01) or period (30d, 2w, 12h)")
+	cmd.Flags().StringVar(&f.position, "position", "", "Only count games in a position: "+strings.Join(stats.Positions, ", "))
+	cmd.Flags().BoolVar(&f.asJSON, "json", false, "Print the statistics as JSON")
+}
+
+// filter builds the stats filter described by the flags
+func (f *statsFlags) filter(cfg *config.Config) (stats.Filter, error) {
+	filter, err := stats.SummonerFilter(cfg, f.summoner)
+	if err != nil {
+		return filter, err
+	}
+	if filter.Since, err = stats.ParseSince(f.since, time.Now()); err != nil {
+		return filter, err
+	}
+	if filter.Position, err = stats.ParsePosition(f.position); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// printJSON writes v to the command output as indented JSON
+func printJSON(cmd *cobra.Command, v any) error {
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func newStatsChampionsCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var flags statsFlags
+	var sortKey string
+	cmd := &cobra.Command{
+		Use:   "champions",
+		Short: "Print games, win rate, KDA, OP score rank, CS and vision per champion",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := flags.filter(rt.app.Config)
+			if err != nil {
+				return err
+			}
+
+			champions, err := stats.Champions(ctx, rt.app.DB, filter)
+			if err != nil {
+				return err
+			}
+			if err := stats.SortChampions(champions, sortKey); err != nil {
+				return err
+			}
+
+			if flags.asJSON {
+				return printJSON(cmd, champions)
+			}
+			if len(champions) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No games found for %s\n", filter.SummonerID)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHAMPION\tGAMES\tWIN%\tK/D/A\tKDA\tKP\tDMG%\tOP RANK\tCS/MIN\tVISION")
+			for _, c := range champions {
+				fmt.Fprintf(w, "%s\t%d\t%.0f%%\t%.1f/%.1f/%.1f\t%.2f\t%.0f%%\t%.0f%%\t%.1f\t%.1f\t%.1f\n",
+					c.Champion, c.Games, c.WinRate*100, c.Kills, c.Deaths, c.Assists, c.KDA, c.KillParticipation*100, c.DamageShare*100,
+					c.OPScoreRank, c.CSPerMinute, c.VisionScore)
+			}
+			return w.Flush()
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().StringVar(&sortKey, "sort", "games", "Sort by "+strings.Join(stats.ChampionSortKeys, ", "))
+	return cmd
+}
+
+func newStatsSynergyCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var flags statsFlags
+	var minGames int
+	cmd := &cobra.Command{
+		Use:   "synergy",
+		Short: "Print the record with each teammate and with champions on either team",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := flags.filter(rt.app.Config)
+			if err != nil {
+				return err
+			}
+
+			synergy, err := stats.ComputeSynergy(ctx, rt.app.DB, filter, stats.SynergyOptions{
+				MinGames: minGames,
+				Tracked:  rt.app.Config.SummonerIDs(),
+			})
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return printJSON(cmd, synergy)
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Overall: %s\n\n", formatRecord(synergy.Overall))
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TEAMMATE\tTRACKED\tRECORD\tKDA\tOP RANK")
+			for _, t := range synergy.Teammates {
+				tracked := ""
+				if t.Tracked {
+					tracked = "yes"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%.1f\n", t.Teammate, tracked, formatRecord(t.Record), t.KDA, t.OPScoreRank)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			for _, side := range []struct {
+				title     string
+				champions []stats.ChampionPresence
+			}{
+				{"WITH", synergy.Allies},
+				{"AGAINST", synergy.Enemies},
+			} {
+				fmt.Fprintln(out)
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintf(w, "%s CHAMPION\tRECORD\n", side.title)
+				for _, c := range side.champions {
+					fmt.Fprintf(w, "%s\t%s\n", c.Champion, formatRecord(c.Record))
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().IntVar(&minGames, "min-games", 2, "Leave out teammates and champions seen in fewer games")
+	return cmd
+}
+
+func newStatsMatchupsCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var flags statsFlags
+	var minGames int
+	cmd := &cobra.Command{
+		Use:   "matchups",
+		Short: "Print the record, gold and damage difference against the lane opponent per position and champion pair",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := flags.filter(rt.app.Config)
+			if err != nil {
+				return err
+			}
+
+			matchups, err := stats.ComputeMatchups(ctx, rt.app.DB, filter, minGames)
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return printJSON(cmd, matchups)
+			}
+
+			out := cmd.OutOrStdout()
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "POSITION\tRECORD\tGOLD DIFF\tDAMAGE DIFF\tLANE SCORE")
+			for _, lane := range matchups.Lanes {
+				fmt.Fprintf(w, "%s\t%s\t%+.0f\t%+.0f\t%.1f\n", lane.Position, formatRecord(lane.Record), lane.GoldDiff, lane.DamageDiff, lane.LaneScore)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(out)
+			w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHAMPION\tOPPONENT\tRECORD\tGOLD DIFF\tDAMAGE DIFF\tLANE SCORE")
+			for _, m := range matchups.Champions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%+.0f\t%+.0f\t%.1f\n", m.Champion, m.Opponent, formatRecord(m.Record), m.GoldDiff, m.DamageDiff, m.LaneScore)
+			}
+			return w.Flush()
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().IntVar(&minGames, "min-games", 1, "Leave out champion pairs seen in fewer games")
+	return cmd
+}
+
+func newStatsBansCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var flags statsFlags
+	var minGames int
+	cmd := &cobra.Command{
+		Use:   "bans",
+		Short: "Print the champions banned by either team and the record when a champion is banned or open",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := flags.filter(rt.app.Config)
+			if err != nil {
+				return err
+			}
+
+			bans, err := stats.ComputeBans(ctx, rt.app.DB, filter, minGames)
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return printJSON(cmd, bans)
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Overall: %s\n", formatRecord(bans.Overall))
+
+			for _, side := range []struct {
+				title     string
+				champions []stats.BannedChampion
+			}{
+				{"BANNED AGAINST US", bans.AgainstUs},
+				{"OUR BANS", bans.OurBans},
+			} {
+				fmt.Fprintln(out)
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintf(w, "%s\tBANS\tBAN RATE\tORDER\tRECORD\n", side.title)
+				for _, b := range side.champions {
+					fmt.Fprintf(w, "%s\t%d\t%.0f%%\t%.1f\t%s\n", b.Champion, b.Bans, b.BanRate*100, b.PickOrder, formatRecord(b.Record))
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintln(out)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHAMPION\tBANNED\tOPEN")
+			for _, i := range bans.Impact {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", i.Champion, formatRecord(i.Banned), formatRecord(i.Open))
+			}
+			return w.Flush()
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().IntVar(&minGames, "min-games", 2, "Leave out champions banned in fewer games")
+	return cmd
+}
+
+func newStatsObjectivesCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var flags statsFlags
+	cmd := &cobra.Command{
+		Use:   "objectives",
+		Short: "Print the record after each first objective, objective counts and team gold difference per side",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := flags.filter(rt.app.Config)
+			if err != nil {
+				return err
+			}
+
+			objectives, err := stats.ComputeObjectives(ctx, rt.app.DB, filter)
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return printJSON(cmd, objectives)
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Overall: %s\n\n", formatRecord(objectives.Overall))
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "FIRST\tTAKEN\tCONCEDED")
+			for _, f := range objectives.Firsts {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", f.Objective, formatRecord(f.Taken), formatRecord(f.Conceded))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(out)
+			w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "OBJECTIVE\tTAKEN\tCONCEDED\tWIN RATE BY COUNT")
+			for _, d := range objectives.Counts {
+				counts := make([]string, len(d.Counts))
+				for i, c := range d.Counts {
+					counts[i] = fmt.Sprintf("%d: %.0f%% of %d", c.Count, c.Record.WinRate*100, c.Record.Games)
+				}
+				fmt.Fprintf(w, "%s\t%.1f\t%.1f\t%s\n", d.Objective, d.Taken, d.Conceded, joinOrDash(counts))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(out)
+			w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SIDE\tRECORD\tGOLD DIFF\tIN WINS\tIN LOSSES")
+			for _, s := range objectives.Sides {
+				fmt.Fprintf(w, "%s\t%s\t%+.0f\t%+.0f\t%+.0f\n", s.Side, formatRecord(s.Record), s.GoldDiff, s.WinDiff, s.LossDiff)
+			}
+			return w.Flush()
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+func newStatsTempoCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var flags statsFlags
+	cmd := &cobra.Command{
+		Use:   "tempo",
+		Short: "Print the record and gold, damage, CS and vision per minute in early, mid and late games",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := flags.filter(rt.app.Config)
+			if err != nil {
+				return err
+			}
+
+			tempo, err := stats.ComputeTempo(ctx, rt.app.DB, filter)
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return printJSON(cmd, tempo)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "LENGTH\tMINUTES\tRECORD\tAVG LENGTH\tGOLD/MIN\tDAMAGE/MIN\tCS/MIN\tVISION/MIN")
+			for _, t := range append(tempo.Buckets, tempo.Overall) {
+				minutes := fmt.Sprintf("%d-%d", t.FromMinute, t.ToMinute)
+				if t.Bucket == tempo.Overall.Bucket {
+					minutes = ""
+				} else if t.ToMinute == 0 {
+					minutes = fmt.Sprintf("%d+", t.FromMinute)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.0f\t%.0f\t%.1f\t%.2f\n", t.Bucket, minutes, formatRecord(t.Record),
+					formatGameLength(int(t.GameLength)), t.PerMinute.Gold, t.PerMinute.Damage, t.PerMinute.CS, t.PerMinute.Vision)
+			}
+			return w.Flush()
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+func newStatsSessionsCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var flags statsFlags
+	var gap time.Duration
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Print play sessions, streaks and the record by game of the session, losses in a row, hour and weekday",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if gap <= 0 {
+				return fmt.Errorf("--gap must be positive")
+			}
+			filter, err := flags.filter(rt.app.Config)
+			if err != nil {
+				return err
+			}
+
+			sessions, err := stats.ComputeSessions(ctx, rt.app.DB, filter, gap, time.Local)
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return printJSON(cmd, sessions)
+			}
+
+			out := cmd.OutOrStdout()
+			for _, streak := range []struct {
+				title  string
+				streak stats.Streak
+			}{
+				{"Current streak", sessions.CurrentStreak},
+				{"Longest win streak", sessions.LongestWinStreak},
+				{"Longest loss streak", sessions.LongestLossStreak},
+			} {
+				if streak.streak.Games == 0 {
+					continue
+				}
+				fmt.Fprintf(out, "%s: %d %s since %s\n", streak.title, streak.streak.Games, streak.streak.Result,
+					streak.streak.Since.Local().Format("2006-01-02 15:04"))
+			}
+
+			fmt.Fprintln(out)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SESSION\tLENGTH\tRECORD\tRESULTS")
+			for i, s := range sessions.Sessions {
+				if i == limit {
+					break
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Start.Local().Format("2006-01-02 15:04"),
+					formatGameLength(int(s.End.Sub(s.Start).Seconds())), formatRecord(s.Record), s.Results)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			for _, table := range []struct {
+				title   string
+				records []stats.GroupRecord
+			}{
+				{"GAME OF SESSION", sessions.BySessionGame},
+				{"LOSSES IN A ROW BEFORE", sessions.AfterLosses},
+				{"HOUR", sessions.ByHour},
+				{"WEEKDAY", sessions.ByWeekday},
+			} {
+				fmt.Fprintln(out)
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintf(w, "%s\tRECORD\tKDA\n", table.title)
+				for _, r := range table.records {
+					fmt.Fprintf(w, "%s\t%s\t%.2f\n", r.Group, formatRecord(r.Record), r.KDA)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().DurationVar(&gap, "gap", stats.DefaultSessionGap, "Longest break between two games of the same session")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Number of sessions to print, newest first")
+	return cmd
+}
+
+// formatRecord formats a record as games, wins and win rate, e.g. "12 games, 7 wins (58%)"
+func formatRecord(r stats.Record) string {
+	return fmt.Sprintf("%d games, %d wins (%.0f%%)", r.Games, r.Wins, r.WinRate*100)
+}
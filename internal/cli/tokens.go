@@ -0,0 +1,103 @@
+// internal/cli/tokens.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"opggvisualizer/internal/api"
+
+	"github.com/spf13/cobra"
+)
+
+func newTokensCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Manage API tokens",
+	}
+	cmd.AddCommand(newTokensCreateCmd(ctx, rt))
+	cmd.AddCommand(newTokensRevokeCmd(ctx, rt))
+	cmd.AddCommand(newTokensListCmd(ctx, rt))
+	return cmd
+}
+
+func newTokensCreateCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an API token and print it once",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			for _, configured := range rt.app.Config.APIServer.Auth.Tokens {
+				if configured.Name == name {
+					return fmt.Errorf("token %q is already defined in the config file", name)
+				}
+			}
+
+			token, hash, err := api.GenerateToken()
+			if err != nil {
+				return fmt.Errorf("failed to generate token: %w", err)
+			}
+			if err := rt.app.DB.InsertAPIToken(ctx, name, hash, time.Now()); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Token:  %s\n", token)
+			fmt.Fprintf(cmd.OutOrStdout(), "SHA256: %s\n", hash)
+			fmt.Fprintln(cmd.OutOrStdout(), "Store the token now, it cannot be shown again.")
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newTokensRevokeCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke <name>",
+		Short: "Revoke an API token created with tokens create",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, configured := range rt.app.Config.APIServer.Auth.Tokens {
+				if configured.Name == args[0] {
+					return fmt.Errorf("token %q is defined in the config file, remove it there", args[0])
+				}
+			}
+			if err := rt.app.DB.RevokeAPIToken(ctx, args[0], time.Now()); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Token %s revoked\n", args[0])
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newTokensListCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List API tokens",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tokens, err := rt.app.DB.ListAPITokens(ctx)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSOURCE\tCREATED\tREVOKED")
+			for _, configured := range rt.app.Config.APIServer.Auth.Tokens {
+				fmt.Fprintf(w, "%s\tconfig\t-\t-\n", configured.Name)
+			}
+			for _, token := range tokens {
+				revoked := "-"
+				if token.RevokedAt != nil {
+					revoked = token.RevokedAt.Format(time.RFC3339)
+				}
+				fmt.Fprintf(w, "%s\tdatabase\t%s\t%s\n", token.Name, token.CreatedAt.Format(time.RFC3339), revoked)
+			}
+			return w.Flush()
+		},
+	}
+	return cmd
+}
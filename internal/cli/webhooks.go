@@ -0,0 +1,113 @@
+// internal/cli/webhooks.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/stats"
+	"opggvisualizer/internal/webhooks"
+
+	"github.com/spf13/cobra"
+)
+
+func newWebhooksCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhooks",
+		Short: "Try the webhooks notified of new games",
+	}
+	cmd.AddCommand(newWebhooksTestCmd(ctx, rt))
+	cmd.AddCommand(newWebhooksReceiveCmd(ctx))
+	return cmd
+}
+
+func newWebhooksTestCmd(ctx context.Context, rt *runtime) *cobra.Command {
+	var summoner, name string
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Send the latest stored game of a summoner to the webhooks",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := stats.SummonerFilter(rt.app.Config, summoner)
+			if err != nil {
+				return err
+			}
+			summonerName := filter.SummonerName
+			if summonerName == "" {
+				summonerName = filter.SummonerID
+			}
+			games, err := rt.app.DB.ListGames(ctx, filter.SummonerID, summonerName, config.DefaultLocale, 1)
+			if err != nil {
+				return err
+			}
+			if len(games) == 0 {
+				return fmt.Errorf("no games stored for %s, run: games fetch", filter.SummonerID)
+			}
+
+			notifier := rt.app.Client.Webhooks
+			var hooks []config.WebhookConfig
+			for _, hook := range notifier.Endpoints(filter.SummonerID) {
+				if name == "" || hook.Name == name {
+					hooks = append(hooks, hook)
+				}
+			}
+			if len(hooks) == 0 && name != "" {
+				return fmt.Errorf("no webhook named %q notified for %s", name, filter.SummonerID)
+			}
+			if len(hooks) == 0 {
+				return fmt.Errorf("no webhook configured for %s", filter.SummonerID)
+			}
+
+			event := webhooks.NewGameEvent(rt.app.Config.Webhooks.BaseURL, filter.SummonerID, games[0])
+			var errs []error
+			for _, hook := range hooks {
+				if err := notifier.Send(ctx, hook, event); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Sent game %s to %s (%s)\n", event.Game.GameID, hook.Name, hook.Format)
+			}
+			return errors.Join(errs...)
+		},
+	}
+	cmd.Flags().StringVar(&summoner, "summoner", "", "Configured summoner id or name, required when several summoners are configured")
+	cmd.Flags().StringVar(&name, "name", "", "Only send to the webhook with this name")
+	return cmd
+}
+
+func newWebhooksReceiveCmd(ctx context.Context) *cobra.Command {
+	var port, failures int
+	cmd := &cobra.Command{
+		Use:   "receive",
+		Short: "Run a local stand-in webhook receiver that prints every delivery",
+		Example: "  opggvisualizer webhooks receive --port 9090 --fail 2\n" +
+			"  WEBHOOK_URL=http://localhost:9090/hook opggvisualizer webhooks test",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server := &http.Server{
+				Addr:    ":" + strconv.Itoa(port),
+				Handler: webhooks.NewReceiver(cmd.OutOrStdout(), failures),
+			}
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- server.ListenAndServe()
+			}()
+			fmt.Fprintf(cmd.OutOrStdout(), "Receiving webhooks at http://localhost:%d/\n", port)
+
+			select {
+			case err := <-errCh:
+				return fmt.Errorf("webhook receiver failed: %w", err)
+			case <-ctx.Done():
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return server.Shutdown(shutdownCtx)
+		},
+	}
+	cmd.Flags().IntVar(&port, "port", 9090, "Port to listen on")
+	cmd.Flags().IntVar(&failures, "fail", 0, "Answer the first deliveries with 503 to exercise the retries")
+	return cmd
+}
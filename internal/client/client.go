@@ -2,32 +2,111 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/db"
+	"opggvisualizer/internal/webhooks"
 )
 
+// Paths are relative to the base URLs in Endpoints
 const (
-	GameDataURL            = "https://lol-web-api.op.gg/api/v1.0/internal/bypass/games/na/summoners/%s?=&limit=20&hl=en_US&game_type=soloranked"
-	ChampionDataURL        = "http://ddragon.leagueoflegends.com/cdn/%s/data/en_US/champion.json"
-	ChampionDataVersionURL = "https://ddragon.leagueoflegends.com/api/versions.json"
+	GameDataPath            = "/api/v1.0/internal/bypass/games/%s/summoners/%s?=&limit=20&hl=en_US&game_type=soloranked"
+	ChampionDataPath        = "/cdn/%s/data/%s/champion.json"
+	ChampionDataVersionPath = "/api/versions.json"
+	ItemDataPath            = "/cdn/%s/data/en_US/item.json"
+	RuneDataPath            = "/cdn/%s/data/en_US/runesReforged.json"
+	SummonerSpellDataPath   = "/cdn/%s/data/en_US/summoner.json"
 )
 
-func FetchData(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+// Endpoints holds the base URLs of the upstream services
+type Endpoints struct {
+	OPGG    string
+	DDragon string
+}
+
+var DefaultEndpoints = Endpoints{
+	OPGG:    "https://lol-web-api.op.gg",
+	DDragon: "https://ddragon.leagueoflegends.com",
+}
+
+// Client fetches data from op.gg and ddragon and stores it in the database
+type Client struct {
+	Config    *config.Config
+	DB        *db.Database
+	HTTP      *http.Client
+	Endpoints Endpoints
+	Webhooks  *webhooks.Notifier // Notified of the new games stored by a games fetch
+}
+
+func New(cfg *config.Config, database *db.Database, endpoints Endpoints) *Client {
+	return &Client{
+		Config:    cfg,
+		DB:        database,
+		HTTP:      &http.Client{Timeout: cfg.HTTPClient.Timeout},
+		Endpoints: endpoints,
+		Webhooks:  webhooks.New(cfg),
+	}
+}
+
+// FetchData performs a GET request using the configured HTTP client settings.
+// Network errors, 429 and 5xx responses are retried.
+func (c *Client) FetchData(ctx context.Context, url string) ([]byte, error) {
+	cfg := c.Config.HTTPClient
+
+	delay := cfg.RetryDelay
+	var lastErr error
+	for attempt := 0; attempt <= cfg.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		data, retry, err := c.fetchOnce(ctx, url)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// fetchOnce performs a single GET request and reports whether a failure is worth retrying
+func (c *Client) fetchOnce(ctx context.Context, url string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.Config.HTTPClient.UserAgent != "" {
+		req.Header.Set("User-Agent", c.Config.HTTPClient.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP GET request failed: %w", err)
+		return nil, ctx.Err() == nil, fmt.Errorf("HTTP GET request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, retry, fmt.Errorf("non-OK HTTP status: %s", resp.Status)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, true, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	return data, nil
+	return data, false, nil
 }
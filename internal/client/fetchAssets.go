@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"opggvisualizer/internal/assets"
+)
+
+// AssetSyncResult counts the images handled by SyncAssets
+type AssetSyncResult struct {
+	Downloaded int
+	Existing   int
+	Failed     int
+}
+
+// SyncAssets downloads the champion, item, rune and summoner spell images of the current
+// ddragon version into store. Images that are already stored are skipped and failed
+// downloads are logged, so an interrupted sync can simply be run again.
+func (c *Client) SyncAssets(ctx context.Context, store *assets.Store) (AssetSyncResult, error) {
+	var result AssetSyncResult
+
+	imageURLs, err := c.DB.ListImageURLs(ctx)
+	if err != nil {
+		return result, err
+	}
+	if len(imageURLs) == 0 {
+		return result, fmt.Errorf("no static data stored, run champions fetch first")
+	}
+
+	for _, imageURL := range imageURLs {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("asset sync interrupted: %w", err)
+		}
+		if store.Has(imageURL) {
+			result.Existing++
+			continue
+		}
+
+		// Stored URLs always point at the public CDN, download from the configured endpoint instead
+		relative, err := assets.RelativePath(imageURL)
+		if err != nil {
+			log.Printf("Error resolving asset %s: %v", imageURL, err)
+			result.Failed++
+			continue
+		}
+		data, err := c.FetchData(ctx, c.Endpoints.DDragon+"/"+relative)
+		if err != nil {
+			log.Printf("Error downloading asset %s: %v", relative, err)
+			result.Failed++
+			continue
+		}
+		if err := store.Write(imageURL, data); err != nil {
+			return result, err
+		}
+		result.Downloaded++
+	}
+
+	log.Printf("Synced assets: %d downloaded, %d already stored, %d failed.", result.Downloaded, result.Existing, result.Failed)
+	return result, nil
+}
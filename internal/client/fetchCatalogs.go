@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"opggvisualizer/internal/models"
+)
+
+// fetchAndStoreCatalogs fetches the item, rune and summoner spell data files for a ddragon version.
+// Items are always added to their version history. Only the latest version updates the current tables.
+func (c *Client) fetchAndStoreCatalogs(ctx context.Context, version string, latest bool) error {
+	if err := c.fetchAndStoreItems(ctx, version, latest); err != nil {
+		return err
+	}
+	if !latest {
+		return nil
+	}
+	if err := c.fetchAndStoreRunes(ctx, version); err != nil {
+		return err
+	}
+	return c.fetchAndStoreSummonerSpells(ctx, version)
+}
+
+func (c *Client) fetchAndStoreItems(ctx context.Context, version string, latest bool) error {
+	itemDataBytes, err := c.FetchData(ctx, c.Endpoints.DDragon+fmt.Sprintf(ItemDataPath, version))
+	if err != nil {
+		return fmt.Errorf("error fetching item data: %w", err)
+	}
+
+	var itemData models.ItemData
+	if err := json.Unmarshal(itemDataBytes, &itemData); err != nil {
+		return fmt.Errorf("error unmarshaling item data: %w", err)
+	}
+
+	log.Printf("Fetched %d items.", len(itemData.Data))
+	for itemID, item := range itemData.Data {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("item data fetch interrupted: %w", err)
+		}
+		if err := c.DB.InsertItemVersion(ctx, itemID, item, version); err != nil {
+			log.Printf("Error inserting item %s version %s: %v", item.Name, version, err)
+			continue
+		}
+		if !latest {
+			continue
+		}
+		if err := c.DB.InsertItem(ctx, itemID, item, version); err != nil {
+			log.Printf("Error inserting item %s: %v", item.Name, err)
+			continue
+		}
+	}
+	return nil
+}
+
+func (c *Client) fetchAndStoreRunes(ctx context.Context, version string) error {
+	runeDataBytes, err := c.FetchData(ctx, c.Endpoints.DDragon+fmt.Sprintf(RuneDataPath, version))
+	if err != nil {
+		return fmt.Errorf("error fetching rune data: %w", err)
+	}
+
+	var runeTrees []models.RuneTree
+	if err := json.Unmarshal(runeDataBytes, &runeTrees); err != nil {
+		return fmt.Errorf("error unmarshaling rune data: %w", err)
+	}
+
+	log.Printf("Fetched %d rune trees.", len(runeTrees))
+	for _, tree := range runeTrees {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("rune data fetch interrupted: %w", err)
+		}
+		if err := c.DB.InsertRuneTree(ctx, tree, version); err != nil {
+			log.Printf("Error inserting rune tree %s: %v", tree.Name, err)
+			continue
+		}
+	}
+	return nil
+}
+
+func (c *Client) fetchAndStoreSummonerSpells(ctx context.Context, version string) error {
+	spellDataBytes, err := c.FetchData(ctx, c.Endpoints.DDragon+fmt.Sprintf(SummonerSpellDataPath, version))
+	if err != nil {
+		return fmt.Errorf("error fetching summoner spell data: %w", err)
+	}
+
+	var spellData models.SummonerSpellData
+	if err := json.Unmarshal(spellDataBytes, &spellData); err != nil {
+		return fmt.Errorf("error unmarshaling summoner spell data: %w", err)
+	}
+
+	log.Printf("Fetched %d summoner spells.", len(spellData.Data))
+	for _, spell := range spellData.Data {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("summoner spell data fetch interrupted: %w", err)
+		}
+		if err := c.DB.InsertSummonerSpell(ctx, spell, version); err != nil {
+			log.Printf("Error inserting summoner spell %s: %v", spell.Name, err)
+			continue
+		}
+	}
+	return nil
+}
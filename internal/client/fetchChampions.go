@@ -1,72 +1,112 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
-	"opggvisualizer/internal/db"
+	"opggvisualizer/internal/config"
 	"opggvisualizer/internal/models"
 	"time"
 )
 
-func FetchAndStoreChampionData() error {
+func (c *Client) FetchAndStoreChampionData(ctx context.Context) error {
+	database := c.DB
+
 	// Check the last time the champion data was updated
-	lastUpdated, err := db.GetDatabaseConnection().GetLastFetch("CHAMPIONS")
+	lastUpdated, err := database.GetLastFetch(ctx, "CHAMPIONS")
 	if err != nil {
 		log.Printf("error getting last fetch time: %v", err) // Log the error, but continue
 	}
 
 	log.Printf("Last champion data update: %v", lastUpdated)
-	if time.Since(lastUpdated) < 24*time.Hour {
+	if time.Since(lastUpdated) < c.Config.Intervals.Champions {
 		log.Println("Champion data is up to date.")
 		return nil
 	}
 
-	// Fetch the latest champion data version
-	versionsBytes, err := FetchData(ChampionDataVersionURL)
+	versions, err := c.FetchVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	latestVersion := versions[0]
+	log.Printf("Latest Champion Data Version: %s", latestVersion)
+
+	if err := c.FetchAndStoreStaticData(ctx, latestVersion); err != nil {
+		return err
+	}
+
+	// Update the last fetch time
+	if err := database.SetLastFetch(ctx, "CHAMPIONS", time.Now()); err != nil {
+		return fmt.Errorf("error updating last fetch time for champions: %w", err)
+	}
+
+	newFetchTime, err := database.GetLastFetch(ctx, "CHAMPIONS")
+	if err != nil {
+		log.Printf("error getting champions last fetch time: %v", err) // Log the error, but continue
+	}
+	log.Printf("Updated last fetch time: %v", newFetchTime)
+
+	return nil
+}
+
+// FetchVersions returns the ddragon versions, newest first
+func (c *Client) FetchVersions(ctx context.Context) ([]string, error) {
+	versionsBytes, err := c.FetchData(ctx, c.Endpoints.DDragon+ChampionDataVersionPath)
 	if err != nil {
-		return fmt.Errorf("error fetching champion data versions: %w", err)
+		return nil, fmt.Errorf("error fetching champion data versions: %w", err)
 	}
 
 	var versions []string
 	if err := json.Unmarshal(versionsBytes, &versions); err != nil {
-		return fmt.Errorf("error unmarshaling champion data versions: %w", err)
+		return nil, fmt.Errorf("error unmarshaling champion data versions: %w", err)
 	}
 
 	if len(versions) == 0 {
-		return fmt.Errorf("no versions found in ChampionDataVersionURL")
+		return nil, fmt.Errorf("no versions found in ChampionDataVersionPath")
 	}
+	return versions, nil
+}
 
-	latestVersion := versions[0]
-	log.Printf("Latest Champion Data Version: %s", latestVersion)
-
-	// Construct the ChampionDataURL with the latest version
-	formattedChampionDataURL := fmt.Sprintf(ChampionDataURL, latestVersion)
+// FetchAndStoreStaticData fetches the champions, items, runes and summoner spells of a ddragon version.
+// Champions and items are added to their version history. The current tables are only
+// updated when no newer version is stored, so fetching an old patch never overwrites them.
+func (c *Client) FetchAndStoreStaticData(ctx context.Context, version string) error {
+	database := c.DB
 
-	// Fetch champion data using the latest version
-	championDataBytes, err := FetchData(formattedChampionDataURL)
+	latest, err := database.IsLatestStaticVersion(ctx, version)
 	if err != nil {
-		return fmt.Errorf("error fetching champion data: %w", err)
+		return err
 	}
 
-	var championData models.ChampionData
-	if err := json.Unmarshal(championDataBytes, &championData); err != nil {
-		return fmt.Errorf("error unmarshaling champion data: %w", err)
+	championData, err := c.fetchChampionData(ctx, version, config.DefaultLocale)
+	if err != nil {
+		return err
 	}
 
-	log.Printf("Fetched %d champions.", len(championData.Data))
+	log.Printf("Fetched %d champions for version %s.", len(championData.Data), version)
 
-	database := db.GetDatabaseConnection()
 	// Insert champions into the database
 	for _, champ := range championData.Data {
-		if err := database.InsertChampion(champ); err != nil {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("champion data fetch interrupted: %w", err)
+		}
+		if err := database.InsertChampionVersion(ctx, champ, version); err != nil {
+			log.Printf("Error inserting champion %s version %s: %v", champ.Name, version, err)
+			continue
+		}
+		if !latest {
+			continue
+		}
+		if err := database.InsertChampion(ctx, champ); err != nil {
 			log.Printf("Error inserting champion %s: %v", champ.Name, err)
 			continue
 		}
 	}
 
 	// List and log all champion_ids after insertion
-	championIDs, err := database.ListChampionIDs()
+	championIDs, err := database.ListChampionIDs(ctx)
 	if err != nil {
 		log.Printf("Error listing champion IDs: %v", err)
 	} else {
@@ -75,16 +115,89 @@ func FetchAndStoreChampionData() error {
 		// log.Printf("Champion IDs: %v", championIDs)
 	}
 
-	// Update the last fetch time
-	if err := database.SetLastFetch("CHAMPIONS", time.Now()); err != nil {
-		return fmt.Errorf("error updating last fetch time for champions: %w", err)
+	if latest {
+		c.fetchAndStoreChampionTranslations(ctx, version)
+	}
+
+	// Items, runes and summoner spells are refreshed together with the champions
+	if err := c.fetchAndStoreCatalogs(ctx, version, latest); err != nil {
+		return err
 	}
 
-	newFetchTime, err := database.GetLastFetch("CHAMPIONS")
+	return database.RecordStaticVersion(ctx, version, time.Now())
+}
+
+// fetchChampionData fetches the champions of a ddragon version in the given locale
+func (c *Client) fetchChampionData(ctx context.Context, version, locale string) (*models.ChampionData, error) {
+	// Construct the champion data URL with the requested version and locale
+	formattedChampionDataURL := c.Endpoints.DDragon + fmt.Sprintf(ChampionDataPath, version, locale)
+
+	championDataBytes, err := c.FetchData(ctx, formattedChampionDataURL)
 	if err != nil {
-		log.Printf("error getting champions last fetch time: %v", err) // Log the error, but continue
+		return nil, fmt.Errorf("error fetching champion data: %w", err)
 	}
-	log.Printf("Updated last fetch time: %v", newFetchTime)
 
+	var championData models.ChampionData
+	if err := json.Unmarshal(championDataBytes, &championData); err != nil {
+		return nil, fmt.Errorf("error unmarshaling champion data: %w", err)
+	}
+	return &championData, nil
+}
+
+// fetchAndStoreChampionTranslations stores the champion names and titles in every configured locale.
+// A locale that fails to fetch is logged and skipped, the default locale remains available.
+func (c *Client) fetchAndStoreChampionTranslations(ctx context.Context, version string) {
+	for _, locale := range c.Config.Locales {
+		if locale == config.DefaultLocale {
+			continue
+		}
+
+		championData, err := c.fetchChampionData(ctx, version, locale)
+		if err != nil {
+			log.Printf("Error fetching %s champion translations: %v", locale, err)
+			continue
+		}
+
+		for _, champ := range championData.Data {
+			if err := c.DB.InsertChampionTranslation(ctx, champ, locale); err != nil {
+				log.Printf("Error inserting %s translation of champion %s: %v", locale, champ.ID, err)
+			}
+		}
+		log.Printf("Stored %d %s champion translations.", len(championData.Data), locale)
+	}
+}
+
+// FetchAndStoreMissingStaticData fetches the static data for every patch of a stored game that has none yet
+func (c *Client) FetchAndStoreMissingStaticData(ctx context.Context) error {
+	patches, err := c.DB.ListPatchesWithoutStaticData(ctx)
+	if err != nil {
+		return err
+	}
+	if len(patches) == 0 {
+		log.Println("Static data is stored for every game patch.")
+		return nil
+	}
+
+	versions, err := c.FetchVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, patch := range patches {
+		// versions is newest first, so the first match is the last release of the patch
+		found := false
+		for _, version := range versions {
+			if models.Patch(version) == patch {
+				if err := c.FetchAndStoreStaticData(ctx, version); err != nil {
+					return err
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Printf("No ddragon version found for patch %s", patch)
+		}
+	}
 	return nil
 }
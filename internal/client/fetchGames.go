@@ -1,34 +1,62 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"opggvisualizer/internal/config"
-	"opggvisualizer/internal/db"
 	"opggvisualizer/internal/models"
+	"opggvisualizer/internal/stats"
+	"sort"
 	"time"
 )
 
-func FetchAndStoreGameData() error {
+// FetchAndStoreGameData fetches and stores the recent games of every configured summoner, then evaluates the goals
+func (c *Client) FetchAndStoreGameData(ctx context.Context) error {
+	var errs []error
+	// Tracked summoners playing together fetch the same games, the game is stored by the first fetch and
+	// still new to the others
+	stored := map[string]bool{}
+	for _, summoner := range c.Config.Summoners {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := c.fetchAndStoreSummonerGameData(ctx, summoner, stored); err != nil {
+			errs = append(errs, fmt.Errorf("summoner %s: %w", summoner.ID, err))
+		}
+	}
+
+	// Goals are evaluated even without new games, a weekly goal restarts on Monday
+	if _, err := stats.EvaluateGoals(ctx, c.Config, c.DB, "", time.Now()); err != nil {
+		errs = append(errs, fmt.Errorf("goals: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// fetchAndStoreSummonerGameData stores the recent games of one summoner and notifies the webhooks of
+// the games new to the summoner. stored holds the games stored so far by this refresh, it is updated.
+func (c *Client) fetchAndStoreSummonerGameData(ctx context.Context, summoner config.Summoner, stored map[string]bool) error {
+	database := c.DB
+	fetchType := gamesFetchType(summoner)
+
 	// Check the last time the game data was updated
-	lastUpdated, err := db.GetDatabaseConnection().GetLastFetch("GAMES")
+	lastUpdated, err := database.GetLastFetch(ctx, fetchType)
 	if err != nil {
 		log.Printf("error getting last fetch time: %v", err) // Log the error, but continue
 	}
 
-	log.Printf("Last game data update: %v", lastUpdated)
-	if time.Since(lastUpdated) < 24*time.Hour {
-		log.Println("Game data is up to date.")
+	log.Printf("Last game data update for %s: %v", summoner.ID, lastUpdated)
+	if time.Since(lastUpdated) < c.Config.Intervals.Games {
+		log.Printf("Game data for %s is up to date.", summoner.ID)
 		return nil
 	}
 
-	// Fetch the configuration
-	cfg := config.GetConfig()
-
 	// Fetch game data
-	gameDataURL := fmt.Sprintf(GameDataURL, cfg.SummonerID)
-	gameDataBytes, err := FetchData(gameDataURL)
+	gameDataURL := c.Endpoints.OPGG + fmt.Sprintf(GameDataPath, summoner.Region, summoner.ID)
+	gameDataBytes, err := c.FetchData(ctx, gameDataURL)
 	if err != nil {
 		return fmt.Errorf("error fetching game data: %w", err)
 	}
@@ -39,9 +67,13 @@ func FetchAndStoreGameData() error {
 	}
 
 	log.Printf("Fetched %d games.", len(gameData.Data))
-	database := db.GetDatabaseConnection()
+	var newGameIDs []string
 	// Insert games, teams, and participants into the database
 	for _, gameEntry := range gameData.Data {
+		// Stop between games rather than logging an insert error for every remaining row
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("game data fetch interrupted: %w", err)
+		}
 		// Parse time fields
 		createdAt, err := time.Parse(time.RFC3339, gameEntry.CreatedAt)
 		if err != nil {
@@ -82,37 +114,53 @@ func FetchAndStoreGameData() error {
 			},
 		}
 
-		if err := database.InsertGame(game); err != nil {
-			log.Printf("Error inserting game %s: %v", game.ID, err)
+		if err := database.InsertGame(ctx, game); err != nil {
+			if stored[game.ID] {
+				newGameIDs = append(newGameIDs, game.ID)
+			} else {
+				log.Printf("Error inserting game %s: %v", game.ID, err)
+			}
 			continue
 		}
+		stored[game.ID] = true
 
 		// Insert teams
 		for _, team := range gameEntry.Teams {
-			if err := database.InsertTeam(game.ID, team); err != nil {
+			if err := database.InsertTeam(ctx, game.ID, team); err != nil {
 				log.Printf("Error inserting team for game %s: %v", game.ID, err)
 				continue
 			}
 		}
 
-		// Log a participant to verify the data types
-		fmt.Printf("Participant: %+v\n", gameEntry.Participants[0])
-
 		// Insert participants
 		for _, participant := range gameEntry.Participants {
-			if err := database.InsertParticipant(game.ID, participant); err != nil {
+			if err := database.InsertParticipant(ctx, game.ID, participant); err != nil {
 				log.Printf("Error inserting participant for game %s: %v", game.ID, err)
 				continue
 			}
 		}
+
+		// Derive the metrics and flags once the teams and participants are stored
+		if err := database.UpdateParticipantMetrics(ctx, game.ID); err != nil {
+			log.Printf("Error updating participant metrics for game %s: %v", game.ID, err)
+		}
+		if err := database.ClassifyGame(ctx, game.ID); err != nil {
+			log.Printf("Error classifying game %s: %v", game.ID, err)
+		}
+		newGameIDs = append(newGameIDs, game.ID)
+	}
+
+	// The first fetch stores the whole recent history, which is not news
+	if !lastUpdated.IsZero() {
+		c.notifyNewGames(ctx, summoner, newGameIDs)
 	}
 
 	// Update the last fetch time
-	if err := database.SetLastFetch("GAMES", time.Now()); err != nil {
+	if err := database.SetLastFetch(ctx, fetchType, time.Now()); err != nil {
 		return fmt.Errorf("error updating last fetch time for games: %w", err)
 	}
 
-	newFetchTime, err := database.GetLastFetch("GAMES")
+	newFetchTime, err := database.GetLastFetch(ctx, fetchType)
 	if err != nil {
 		log.Printf("error getting games last fetch time: %v", err) // Log the error, but continue
 	}
@@ -120,3 +168,34 @@ func FetchAndStoreGameData() error {
 
 	return nil
 }
+
+// notifyNewGames sends the games stored by a fetch to the webhooks, oldest first. Delivery failures
+// are logged, the games stay stored either way.
+func (c *Client) notifyNewGames(ctx context.Context, summoner config.Summoner, gameIDs []string) {
+	if len(c.Webhooks.Endpoints(summoner.ID)) == 0 {
+		return
+	}
+	var games []models.GameSummary
+	for _, id := range gameIDs {
+		game, err := c.DB.GetGameSummary(ctx, id, summoner.ID, summoner.Name, config.DefaultLocale)
+		if err != nil {
+			log.Printf("Error loading game %s for webhooks: %v", id, err)
+			continue
+		}
+		if game != nil {
+			games = append(games, *game)
+		}
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].CreatedAt.Before(games[j].CreatedAt) })
+
+	for _, game := range games {
+		if err := c.Webhooks.NotifyGame(ctx, summoner.ID, game); err != nil {
+			log.Printf("Error notifying webhooks of game %s: %v", game.GameID, err)
+		}
+	}
+}
+
+// gamesFetchType is the fetch table key for a summoner's games. Each summoner is refreshed independently.
+func gamesFetchType(summoner config.Summoner) string {
+	return "GAMES:" + summoner.Region + ":" + summoner.ID
+}
@@ -0,0 +1,300 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/db"
+	"opggvisualizer/internal/webhooks"
+)
+
+const testVersion = "14.24.1"
+
+// testChampions are the champions served by the ddragon stand-in, by key
+var testChampions = map[int]string{
+	266: "Aatrox", 103: "Ahri", 84: "Akali", 12: "Alistar", 32: "Amumu",
+	1: "Annie", 22: "Ashe", 63: "Brand", 51: "Caitlyn", 122: "Darius",
+	81: "Ezreal", 86: "Garen",
+}
+
+// upstream stands in for op.gg and ddragon. The games of each summoner are served from games, and
+// the first failures requests for games are answered with 503.
+type upstream struct {
+	mu       sync.Mutex
+	games    map[string][]map[string]any // By summoner id
+	failures int
+	requests map[string]int // Game requests by summoner id
+}
+
+func newUpstream(t *testing.T) (*upstream, *httptest.Server) {
+	u := &upstream{games: map[string][]map[string]any{}, requests: map[string]int{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1.0/internal/bypass/games/{region}/summoners/{id}", func(w http.ResponseWriter, r *http.Request) {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		id := r.PathValue("id")
+		u.requests[id]++
+		if u.failures > 0 {
+			u.failures--
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		games := u.games[id]
+		if games == nil {
+			games = []map[string]any{}
+		}
+		writeTestJSON(w, map[string]any{
+			"meta": map[string]any{"first_game_created_at": "2024-11-04T18:00:00Z", "last_game_created_at": "2024-11-05T18:00:00Z"},
+			"data": games,
+		})
+	})
+	mux.HandleFunc("GET /api/versions.json", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, []string{testVersion})
+	})
+	mux.HandleFunc("GET /cdn/{version}/data/{locale}/{file}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("file") {
+		case "champion.json":
+			data := map[string]any{}
+			for key, name := range testChampions {
+				data[name] = map[string]any{"id": name, "key": fmt.Sprint(key), "name": name, "image": map[string]any{"full": name + ".png"}}
+			}
+			writeTestJSON(w, map[string]any{"type": "champion", "version": testVersion, "data": data})
+		case "item.json", "summoner.json":
+			writeTestJSON(w, map[string]any{"version": testVersion, "data": map[string]any{}})
+		case "runesReforged.json":
+			writeTestJSON(w, []any{})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return u, server
+}
+
+func writeTestJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// testGame builds an op.gg game in which players, named "name:summonerID", play for the blue team
+// with the champions of testChampions in key order. The red team is filled with unknown players.
+func testGame(id string, createdAt time.Time, players ...string) map[string]any {
+	keys := []int{266, 103, 84, 12, 32, 1, 22, 63, 51, 122}
+	participants := []map[string]any{}
+	for i := 0; i < 10; i++ {
+		team, result := "BLUE", "WIN"
+		if i >= 5 {
+			team, result = "RED", "LOSE"
+		}
+		name, summonerID := fmt.Sprintf("%s-p%d", id, i), fmt.Sprintf("%s-sid%d", id, i)
+		if i < len(players) {
+			name, summonerID, _ = strings.Cut(players[i], ":")
+		}
+		participants = append(participants, map[string]any{
+			"participant_id": i + 1,
+			"champion_id":    keys[i],
+			"position":       []string{"TOP", "JUNGLE", "MID", "ADC", "SUPPORT"}[i%5],
+			"team_key":       team,
+			"summoner":       map[string]any{"name": name, "summoner_id": summonerID},
+			"stats": map[string]any{
+				"result": result, "kill": 3, "death": 2, "assist": 5, "gold_earned": 10000,
+				"total_damage_dealt_to_champions": 15000, "vision_score": 20, "minion_kill": 150, "op_score_rank": i%5 + 1,
+			},
+			"items":  []int{},
+			"spells": []int{},
+		})
+	}
+	team := func(key string, win bool, bans []int) map[string]any {
+		return map[string]any{
+			"key":              key,
+			"banned_champions": bans,
+			"game_stat":        map[string]any{"is_win": win, "kill": 15, "death": 10, "assist": 25, "gold_earned": 50000, "tower_kill": 8},
+		}
+	}
+	return map[string]any{
+		"id":                 id,
+		"created_at":         createdAt.Format(time.RFC3339),
+		"game_length_second": 1800,
+		"version":            testVersion,
+		"meta_version":       "14.24",
+		"game_type":          "SOLORANKED",
+		"teams":              []map[string]any{team("BLUE", true, []int{81, 86, -1, 1, 22}), team("RED", false, []int{63, 51, 122, 32, 12})},
+		"participants":       participants,
+	}
+}
+
+func newTestClient(t *testing.T, server *httptest.Server, summoners ...config.Summoner) *Client {
+	ctx := context.Background()
+	database, err := db.Open(ctx, filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	cfg := &config.Config{
+		Summoners: summoners,
+		Locales:   []string{config.DefaultLocale},
+		HTTPClient: config.HTTPClientConfig{
+			Timeout:    5 * time.Second,
+			Retries:    2,
+			RetryDelay: time.Millisecond,
+		},
+		Webhooks: config.WebhooksConfig{Retries: 1, RetryDelay: time.Millisecond},
+	}
+	return New(cfg, database, Endpoints{OPGG: server.URL, DDragon: server.URL})
+}
+
+func count(t *testing.T, c *Client, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := c.DB.Conn.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+func TestFetchAndStoreGameData(t *testing.T) {
+	ctx := context.Background()
+	u, server := newUpstream(t)
+	start := time.Date(2024, 11, 4, 18, 0, 0, 0, time.UTC)
+	u.games["sid-me"] = []map[string]any{
+		testGame("game-1", start, "Me:sid-me"),
+		testGame("game-2", start.Add(time.Hour), "Me:sid-me"),
+	}
+	u.failures = 1
+
+	c := newTestClient(t, server, config.Summoner{ID: "sid-me", Name: "Me", Region: "euw"})
+	if err := c.FetchAndStoreChampionData(ctx); err != nil {
+		t.Fatalf("FetchAndStoreChampionData: %v", err)
+	}
+	if err := c.FetchAndStoreGameData(ctx); err != nil {
+		t.Fatalf("FetchAndStoreGameData: %v", err)
+	}
+
+	if got := u.requests["sid-me"]; got != 2 {
+		t.Errorf("games requested %d times, want 2 (a 503 then a retry)", got)
+	}
+	if got := count(t, c, `SELECT COUNT(*) FROM games`); got != 2 {
+		t.Errorf("stored %d games, want 2", got)
+	}
+	if got := count(t, c, `SELECT COUNT(*) FROM teams`); got != 4 {
+		t.Errorf("stored %d teams, want 4", got)
+	}
+	if got := count(t, c, `SELECT COUNT(*) FROM participants`); got != 20 {
+		t.Errorf("stored %d participants, want 20", got)
+	}
+	if got := count(t, c, `SELECT COUNT(*) FROM participants WHERE summoner_id = ? AND summoner_name = ?`, "sid-me", "Me"); got != 2 {
+		t.Errorf("stored %d participants of the tracked summoner, want 2", got)
+	}
+	// The missed ban (-1) keeps its pick order but is left out of game_bans
+	if got := count(t, c, `SELECT COUNT(*) FROM team_banned_champions`); got != 20 {
+		t.Errorf("stored %d bans, want 20", got)
+	}
+	if got := count(t, c, `SELECT COUNT(*) FROM game_bans WHERE champion_name IS NOT NULL`); got != 18 {
+		t.Errorf("stored %d named bans, want 18", got)
+	}
+	if got := count(t, c, `SELECT pick_order FROM game_bans WHERE game_id = 'game-1' AND champion_id = 1`); got != 4 {
+		t.Errorf("ban of Annie has pick order %d, want 4", got)
+	}
+	if got := count(t, c, `SELECT COUNT(*) FROM participant_metrics`); got != 20 {
+		t.Errorf("stored metrics of %d participants, want 20", got)
+	}
+}
+
+func TestFetchAndStoreGameDataGivesUpAfterRetries(t *testing.T) {
+	ctx := context.Background()
+	u, server := newUpstream(t)
+	u.games["sid-me"] = []map[string]any{testGame("game-1", time.Now(), "Me:sid-me")}
+	u.failures = 10
+
+	c := newTestClient(t, server, config.Summoner{ID: "sid-me", Name: "Me", Region: "euw"})
+	err := c.FetchAndStoreGameData(ctx)
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("FetchAndStoreGameData returned %v, want the 503", err)
+	}
+	if got := u.requests["sid-me"]; got != 3 {
+		t.Errorf("games requested %d times, want 3 (the request and 2 retries)", got)
+	}
+	if got := count(t, c, `SELECT COUNT(*) FROM games`); got != 0 {
+		t.Errorf("stored %d games, want 0", got)
+	}
+	// The fetch time is only recorded on success, the next refresh tries again
+	if last, _ := c.DB.GetLastFetch(ctx, gamesFetchType(c.Config.Summoners[0])); !last.IsZero() {
+		t.Errorf("last fetch recorded at %v after a failed fetch", last)
+	}
+}
+
+func TestFetchAndStoreGameDataNotifiesEverySummoner(t *testing.T) {
+	ctx := context.Background()
+	u, server := newUpstream(t)
+	shared := testGame("game-1", time.Now().Add(-time.Hour), "Me:sid-me", "Duo:sid-duo")
+	u.games["sid-me"] = []map[string]any{shared}
+	u.games["sid-duo"] = []map[string]any{shared, testGame("game-2", time.Now().Add(-2*time.Hour), "Duo:sid-duo")}
+
+	var mu sync.Mutex
+	notified := map[string][]string{} // Game ids by summoner id
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			SummonerID string `json:"summoner_id"`
+			Game       struct {
+				GameID string `json:"game_id"`
+			} `json:"game"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("invalid webhook body: %v", err)
+		}
+		mu.Lock()
+		notified[event.SummonerID] = append(notified[event.SummonerID], event.Game.GameID)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+
+	me := config.Summoner{ID: "sid-me", Name: "Me", Region: "euw"}
+	duo := config.Summoner{ID: "sid-duo", Name: "Duo", Region: "euw"}
+	c := newTestClient(t, server, me, duo)
+	if err := c.FetchAndStoreChampionData(ctx); err != nil {
+		t.Fatalf("FetchAndStoreChampionData: %v", err)
+	}
+	c.Config.Webhooks.Endpoints = []config.WebhookConfig{{Name: "test", URL: receiver.URL, Format: config.WebhookFormatJSON}}
+	c.Webhooks = webhooks.New(c.Config)
+	// Games stored by the first fetch of a summoner are not announced
+	for _, s := range c.Config.Summoners {
+		if err := c.DB.SetLastFetch(ctx, gamesFetchType(s), time.Now().Add(-24*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.FetchAndStoreGameData(ctx); err != nil {
+		t.Fatalf("FetchAndStoreGameData: %v", err)
+	}
+
+	if got := fmt.Sprint(notified["sid-me"]); got != "[game-1]" {
+		t.Errorf("notified games of Me = %s, want [game-1]", got)
+	}
+	// Oldest first, the shared game is new to Duo although Me's fetch stored it
+	if got := fmt.Sprint(notified["sid-duo"]); got != "[game-2 game-1]" {
+		t.Errorf("notified games of Duo = %s, want [game-2 game-1]", got)
+	}
+
+	// Games already stored by an earlier refresh are not announced again
+	notified = map[string][]string{}
+	for _, s := range c.Config.Summoners {
+		c.DB.SetLastFetch(ctx, gamesFetchType(s), time.Now().Add(-24*time.Hour))
+	}
+	if err := c.FetchAndStoreGameData(ctx); err != nil {
+		t.Fatalf("FetchAndStoreGameData: %v", err)
+	}
+	if len(notified) != 0 {
+		t.Errorf("notified games %v on a refresh without new games", notified)
+	}
+}
@@ -2,56 +2,555 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"opggvisualizer/internal/models"
+
+	"gopkg.in/yaml.v3"
 )
 
-var config *Config
+// DefaultConfigPath is read when no config file is given on the command line or in CONFIG_PATH.
+// A missing default file is not an error.
+const DefaultConfigPath = "config.yaml"
+
+// DefaultLocale is always fetched. It is stored in the champions table and used when a translation is missing.
+const DefaultLocale = "en_US"
 
 type Config struct {
+	Region       string           `yaml:"region"` // Default region for summoners that do not set one
+	Summoners    []Summoner       `yaml:"summoners"`
+	Locales      []string         `yaml:"locales"` // ddragon locales to store champion translations for, e.g. "de_DE"
+	DatabasePath string           `yaml:"database_path"`
+	Intervals    IntervalsConfig  `yaml:"intervals"`
+	HTTPClient   HTTPClientConfig `yaml:"http_client"`
+	APIServer    APIConfig        `yaml:"api"`
+	Assets       AssetsConfig     `yaml:"assets"`
+	Stats        StatsConfig      `yaml:"stats"`
+	Webhooks     WebhooksConfig   `yaml:"webhooks"`
+	Reports      ReportsConfig    `yaml:"reports"`
+}
+
+// Summoner is a tracked op.gg summoner
+type Summoner struct {
+	ID     string `yaml:"id"`     // The op.gg summoner id
+	Name   string `yaml:"name"`   // Optional display name
+	Region string `yaml:"region"` // op.gg region, e.g. "na" or "euw"
+}
+
+// IntervalsConfig holds the minimum time between two fetches of the same data
+type IntervalsConfig struct {
+	Champions time.Duration `yaml:"champions"`
+	Games     time.Duration `yaml:"games"`
+}
+
+// HTTPClientConfig controls the client used to call op.gg and ddragon
+type HTTPClientConfig struct {
+	Timeout    time.Duration `yaml:"timeout"`
+	UserAgent  string        `yaml:"user_agent"`
+	Retries    int           `yaml:"retries"`     // Additional attempts after a failed request
+	RetryDelay time.Duration `yaml:"retry_delay"` // Delay before the first retry, doubled on each attempt
+}
+
+// AssetsConfig controls the local mirror of ddragon images
+type AssetsConfig struct {
+	Dir string `yaml:"dir"` // Filled by "assets sync" and served under /assets/
+}
+
+// StatsConfig controls which games the statistics count
+type StatsConfig struct {
+	Exclude []string `yaml:"exclude"` // Game flags, e.g. "remake", of the games left out
+}
+
+// WebhooksConfig lists the webhooks notified when new games are stored
+type WebhooksConfig struct {
+	BaseURL    string          `yaml:"base_url"`    // Public URL of the API server, used for the links in notifications
+	Retries    int             `yaml:"retries"`     // Additional attempts after a failed delivery
+	RetryDelay time.Duration   `yaml:"retry_delay"` // Delay before the first retry, doubled on each attempt
+	Endpoints  []WebhookConfig `yaml:"endpoints"`
+}
+
+// WebhookConfig is one receiver of game notifications
+type WebhookConfig struct {
+	Name      string   `yaml:"name"`
+	URL       string   `yaml:"url"`
+	Format    string   `yaml:"format"`    // json, discord or slack
+	Summoners []string `yaml:"summoners"` // Ids of the summoners notified about, every configured summoner if empty
+}
+
+// Webhook payload formats
+const (
+	WebhookFormatJSON    = "json"
+	WebhookFormatDiscord = "discord"
+	WebhookFormatSlack   = "slack"
+)
+
+// WebhookFormats lists the supported webhook payload formats
+var WebhookFormats = []string{WebhookFormatJSON, WebhookFormatDiscord, WebhookFormatSlack}
+
+// ReportsConfig controls the weekly reports
+type ReportsConfig struct {
+	Format string      `yaml:"format"` // html or markdown
+	Dir    string      `yaml:"dir"`    // Reports published by "report weekly --publish" and POST /reports/weekly are written here
+	Email  EmailConfig `yaml:"email"`
+}
+
+// EmailConfig is the SMTP server published reports are sent through
+type EmailConfig struct {
+	SMTPAddr string   `yaml:"smtp_addr"` // host:port, empty disables email
+	Username string   `yaml:"username"`  // Leave empty for servers without authentication. Requires STARTTLS unless the server is on localhost
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// Report formats
+const (
+	ReportFormatHTML     = "html"
+	ReportFormatMarkdown = "markdown"
+)
+
+// ReportFormats lists the supported report formats
+var ReportFormats = []string{ReportFormatHTML, ReportFormatMarkdown}
+
+type APIConfig struct {
+	Port                string          `yaml:"port"`
+	PIDFile             string          `yaml:"pid_file"`              // Used by "server stop" to find the running server
+	ShutdownGracePeriod time.Duration   `yaml:"shutdown_grace_period"` // Time given to in-flight requests and refreshes before they are cancelled
+	Auth                AuthConfig      `yaml:"auth"`
+	RateLimit           RateLimitConfig `yaml:"rate_limit"`
+}
+
+// RateLimitConfig limits the requests accepted per token, or per address for anonymous reads
+type RateLimitConfig struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"` // 0 disables rate limiting
+	Burst             int `yaml:"burst"`
+}
+
+// AuthConfig lists the bearer tokens accepted by the API server
+type AuthConfig struct {
+	Tokens       []TokenConfig `yaml:"tokens"`
+	ProtectReads bool          `yaml:"protect_reads"` // Require a token on read endpoints as well
+}
+
+// TokenConfig is a static API token. Only the hex encoded SHA-256 hash of the token is stored.
+type TokenConfig struct {
+	Name   string `yaml:"name"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// Overrides holds values set on the command line. Empty fields are ignored.
+type Overrides struct {
 	SummonerID   string
+	Region       string
 	DatabasePath string
-	APIServer    apiConfig
+	APIPort      string
 }
 
-type apiConfig struct {
-	Port string
+// Regions supported by op.gg
+var Regions = []string{"na", "euw", "eune", "kr", "jp", "br", "lan", "las", "oce", "ru", "tr", "ph", "sg", "th", "tw", "vn", "me"}
+
+func defaultConfig() *Config {
+	return &Config{
+		Region:       "na",
+		Locales:      []string{DefaultLocale},
+		DatabasePath: "data.db",
+		Intervals: IntervalsConfig{
+			Champions: 24 * time.Hour,
+			Games:     24 * time.Hour,
+		},
+		HTTPClient: HTTPClientConfig{
+			Timeout:    30 * time.Second,
+			UserAgent:  "opggvisualizer",
+			Retries:    2,
+			RetryDelay: time.Second,
+		},
+		APIServer: APIConfig{
+			Port:                "8080",
+			PIDFile:             "opggvisualizer.pid",
+			ShutdownGracePeriod: 30 * time.Second,
+			RateLimit: RateLimitConfig{
+				RequestsPerMinute: 60,
+				Burst:             10,
+			},
+		},
+		Assets: AssetsConfig{
+			Dir: "assets",
+		},
+		Stats: StatsConfig{
+			Exclude: []string{models.FlagRemake},
+		},
+		Webhooks: WebhooksConfig{
+			Retries:    3,
+			RetryDelay: 2 * time.Second,
+		},
+		Reports: ReportsConfig{
+			Format: ReportFormatHTML,
+			Dir:    "reports",
+		},
+	}
+}
+
+// Load builds the configuration from, in increasing order of precedence,
+// defaults, the config file, environment variables and the given overrides.
+// If path is empty, CONFIG_PATH and then DefaultConfigPath are tried.
+func Load(path string, overrides Overrides) (*Config, error) {
+	cfg := defaultConfig()
+
+	if err := cfg.loadFile(path); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	cfg.applyOverrides(overrides)
+	cfg.normalize()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
-func loadConfig() (*Config, error) {
-	summonerID := os.Getenv("SUMMONER_ID")
-	if summonerID == "" {
-		return nil, fmt.Errorf("SUMMONER_ID is not set")
+func (cfg *Config) loadFile(path string) error {
+	explicit := true
+	if path == "" {
+		path = getEnv("CONFIG_PATH", "")
+	}
+	if path == "" {
+		path = DefaultConfigPath
+		explicit = false
 	}
 
-	databasePath := os.Getenv("DATABASE_PATH")
-	if databasePath == "" {
-		databasePath = "data.db"
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	return &Config{
-		SummonerID:   summonerID,
-		DatabasePath: databasePath,
-		APIServer: apiConfig{
-			Port: getEnv("API_PORT", "8080"),
-		},
-	}, nil
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
 }
 
-func GetConfig() *Config {
-	if config == nil {
-		cfg, err := loadConfig()
+func (cfg *Config) loadEnv() error {
+	if ids := os.Getenv("SUMMONER_ID"); ids != "" {
+		cfg.Summoners = nil
+		for _, id := range strings.Split(ids, ",") {
+			cfg.Summoners = append(cfg.Summoners, Summoner{ID: strings.TrimSpace(id)})
+		}
+	}
+	if to := os.Getenv("REPORT_EMAIL_TO"); to != "" {
+		cfg.Reports.Email.To = nil
+		for _, address := range strings.Split(to, ",") {
+			cfg.Reports.Email.To = append(cfg.Reports.Email.To, strings.TrimSpace(address))
+		}
+	}
+	if locales := os.Getenv("LOCALES"); locales != "" {
+		cfg.Locales = nil
+		for _, locale := range strings.Split(locales, ",") {
+			cfg.Locales = append(cfg.Locales, strings.TrimSpace(locale))
+		}
+	}
+	if exclude := os.Getenv("STATS_EXCLUDE"); exclude != "" {
+		// "none" counts every game, an empty variable keeps the default
+		cfg.Stats.Exclude = nil
+		for _, flag := range strings.Split(exclude, ",") {
+			if flag = strings.TrimSpace(flag); flag != "none" {
+				cfg.Stats.Exclude = append(cfg.Stats.Exclude, flag)
+			}
+		}
+	}
+	cfg.Region = getEnv("REGION", cfg.Region)
+	cfg.DatabasePath = getEnv("DATABASE_PATH", cfg.DatabasePath)
+	cfg.APIServer.Port = getEnv("API_PORT", cfg.APIServer.Port)
+	cfg.APIServer.PIDFile = getEnv("PID_FILE", cfg.APIServer.PIDFile)
+	cfg.HTTPClient.UserAgent = getEnv("HTTP_USER_AGENT", cfg.HTTPClient.UserAgent)
+	cfg.Assets.Dir = getEnv("ASSETS_DIR", cfg.Assets.Dir)
+	cfg.Webhooks.BaseURL = getEnv("WEBHOOK_BASE_URL", cfg.Webhooks.BaseURL)
+	cfg.Reports.Format = getEnv("REPORT_FORMAT", cfg.Reports.Format)
+	cfg.Reports.Dir = getEnv("REPORTS_DIR", cfg.Reports.Dir)
+	cfg.Reports.Email.SMTPAddr = getEnv("SMTP_ADDR", cfg.Reports.Email.SMTPAddr)
+	cfg.Reports.Email.Username = getEnv("SMTP_USERNAME", cfg.Reports.Email.Username)
+	cfg.Reports.Email.Password = getEnv("SMTP_PASSWORD", cfg.Reports.Email.Password)
+	cfg.Reports.Email.From = getEnv("REPORT_EMAIL_FROM", cfg.Reports.Email.From)
+	if hookURL := os.Getenv("WEBHOOK_URL"); hookURL != "" {
+		// A single webhook for docker-compose setups, added to the ones in the config file
+		cfg.Webhooks.Endpoints = append(cfg.Webhooks.Endpoints, WebhookConfig{
+			Name:   "env",
+			URL:    hookURL,
+			Format: getEnv("WEBHOOK_FORMAT", WebhookFormatJSON),
+		})
+	}
+
+	durations := map[string]*time.Duration{
+		"HTTP_TIMEOUT":             &cfg.HTTPClient.Timeout,
+		"FETCH_INTERVAL_CHAMPIONS": &cfg.Intervals.Champions,
+		"FETCH_INTERVAL_GAMES":     &cfg.Intervals.Games,
+		"SHUTDOWN_GRACE_PERIOD":    &cfg.APIServer.ShutdownGracePeriod,
+	}
+	for key, target := range durations {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+		*target = d
+	}
+
+	if value := os.Getenv("HTTP_RETRIES"); value != "" {
+		retries, err := strconv.Atoi(value)
 		if err != nil {
-			log.Fatalf("Error loading config: %v", err)
+			return fmt.Errorf("invalid HTTP_RETRIES: %w", err)
+		}
+		cfg.HTTPClient.Retries = retries
+	}
+	return nil
+}
+
+func (cfg *Config) applyOverrides(o Overrides) {
+	if o.SummonerID != "" {
+		cfg.Summoners = []Summoner{{ID: o.SummonerID}}
+	}
+	if o.Region != "" {
+		cfg.Region = o.Region
+	}
+	if o.DatabasePath != "" {
+		cfg.DatabasePath = o.DatabasePath
+	}
+	if o.APIPort != "" {
+		cfg.APIServer.Port = o.APIPort
+	}
+}
+
+// normalize fills in values derived from other settings
+func (cfg *Config) normalize() {
+	cfg.Region = strings.ToLower(cfg.Region)
+	for i := range cfg.Summoners {
+		if cfg.Summoners[i].Region == "" {
+			cfg.Summoners[i].Region = cfg.Region
+		}
+		cfg.Summoners[i].Region = strings.ToLower(cfg.Summoners[i].Region)
+	}
+	for i := range cfg.APIServer.Auth.Tokens {
+		cfg.APIServer.Auth.Tokens[i].SHA256 = strings.ToLower(cfg.APIServer.Auth.Tokens[i].SHA256)
+	}
+	cfg.Reports.Format = strings.ToLower(cfg.Reports.Format)
+	if cfg.Webhooks.BaseURL == "" {
+		cfg.Webhooks.BaseURL = "http://localhost:" + cfg.APIServer.Port
+	}
+	cfg.Webhooks.BaseURL = strings.TrimSuffix(cfg.Webhooks.BaseURL, "/")
+	for i := range cfg.Webhooks.Endpoints {
+		if cfg.Webhooks.Endpoints[i].Format == "" {
+			cfg.Webhooks.Endpoints[i].Format = WebhookFormatJSON
+		}
+		cfg.Webhooks.Endpoints[i].Format = strings.ToLower(cfg.Webhooks.Endpoints[i].Format)
+	}
+}
+
+// Validate reports every problem found in the configuration
+func (cfg *Config) Validate() error {
+	var errs []error
+
+	if len(cfg.Summoners) == 0 {
+		errs = append(errs, fmt.Errorf("no summoners configured, set SUMMONER_ID or summoners in the config file"))
+	}
+	seen := make(map[string]bool)
+	for i, s := range cfg.Summoners {
+		if s.ID == "" {
+			errs = append(errs, fmt.Errorf("summoners[%d]: id is required", i))
+		}
+		if seen[s.ID] {
+			errs = append(errs, fmt.Errorf("summoners[%d]: duplicate id %q", i, s.ID))
+		}
+		seen[s.ID] = true
+		if !validRegion(s.Region) {
+			errs = append(errs, fmt.Errorf("summoners[%d]: unknown region %q", i, s.Region))
+		}
+	}
+	if !validRegion(cfg.Region) {
+		errs = append(errs, fmt.Errorf("region: unknown region %q", cfg.Region))
+	}
+
+	for i, locale := range cfg.Locales {
+		if !localePattern.MatchString(locale) {
+			errs = append(errs, fmt.Errorf("locales[%d]: invalid locale %q, expected a ddragon locale such as \"de_DE\"", i, locale))
+		}
+	}
+
+	if cfg.DatabasePath == "" {
+		errs = append(errs, fmt.Errorf("database_path must not be empty"))
+	}
+
+	if cfg.Intervals.Champions < 0 {
+		errs = append(errs, fmt.Errorf("intervals.champions must not be negative"))
+	}
+	if cfg.Intervals.Games < 0 {
+		errs = append(errs, fmt.Errorf("intervals.games must not be negative"))
+	}
+
+	if cfg.HTTPClient.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("http_client.timeout must be positive"))
+	}
+	if cfg.HTTPClient.Retries < 0 {
+		errs = append(errs, fmt.Errorf("http_client.retries must not be negative"))
+	}
+	if cfg.HTTPClient.RetryDelay < 0 {
+		errs = append(errs, fmt.Errorf("http_client.retry_delay must not be negative"))
+	}
+
+	if port, err := strconv.Atoi(cfg.APIServer.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("api.port: invalid port %q", cfg.APIServer.Port))
+	}
+	if cfg.APIServer.PIDFile == "" {
+		errs = append(errs, fmt.Errorf("api.pid_file must not be empty"))
+	}
+	if cfg.APIServer.ShutdownGracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("api.shutdown_grace_period must not be negative"))
+	}
+	if cfg.APIServer.RateLimit.RequestsPerMinute < 0 {
+		errs = append(errs, fmt.Errorf("api.rate_limit.requests_per_minute must not be negative"))
+	}
+	if cfg.APIServer.RateLimit.RequestsPerMinute > 0 && cfg.APIServer.RateLimit.Burst < 1 {
+		errs = append(errs, fmt.Errorf("api.rate_limit.burst must be at least 1"))
+	}
+	for i, token := range cfg.APIServer.Auth.Tokens {
+		if token.Name == "" {
+			errs = append(errs, fmt.Errorf("api.auth.tokens[%d]: name is required", i))
+		}
+		if !validSHA256(token.SHA256) {
+			errs = append(errs, fmt.Errorf("api.auth.tokens[%d]: sha256 must be a hex encoded SHA-256 hash", i))
+		}
+	}
+
+	if cfg.Assets.Dir == "" {
+		errs = append(errs, fmt.Errorf("assets.dir must not be empty"))
+	}
+
+	for i, flag := range cfg.Stats.Exclude {
+		if !slices.Contains(models.GameFlags, flag) {
+			errs = append(errs, fmt.Errorf("stats.exclude[%d]: unknown game flag %q, expected one of %s", i, flag, strings.Join(models.GameFlags, ", ")))
+		}
+	}
+
+	if !validHTTPURL(cfg.Webhooks.BaseURL) {
+		errs = append(errs, fmt.Errorf("webhooks.base_url: invalid URL %q", cfg.Webhooks.BaseURL))
+	}
+	if cfg.Webhooks.Retries < 0 {
+		errs = append(errs, fmt.Errorf("webhooks.retries must not be negative"))
+	}
+	if cfg.Webhooks.RetryDelay < 0 {
+		errs = append(errs, fmt.Errorf("webhooks.retry_delay must not be negative"))
+	}
+	webhookNames := make(map[string]bool)
+	for i, hook := range cfg.Webhooks.Endpoints {
+		if hook.Name == "" {
+			errs = append(errs, fmt.Errorf("webhooks.endpoints[%d]: name is required", i))
+		}
+		if webhookNames[hook.Name] {
+			errs = append(errs, fmt.Errorf("webhooks.endpoints[%d]: duplicate name %q", i, hook.Name))
+		}
+		webhookNames[hook.Name] = true
+		if !validHTTPURL(hook.URL) {
+			errs = append(errs, fmt.Errorf("webhooks.endpoints[%d]: invalid URL %q", i, hook.URL))
+		}
+		if !slices.Contains(WebhookFormats, hook.Format) {
+			errs = append(errs, fmt.Errorf("webhooks.endpoints[%d]: unknown format %q, expected one of %s", i, hook.Format, strings.Join(WebhookFormats, ", ")))
+		}
+		for _, id := range hook.Summoners {
+			if !seen[id] {
+				errs = append(errs, fmt.Errorf("webhooks.endpoints[%d]: summoner %q is not configured", i, id))
+			}
+		}
+	}
+
+	if !slices.Contains(ReportFormats, cfg.Reports.Format) {
+		errs = append(errs, fmt.Errorf("reports.format: unknown format %q, expected one of %s", cfg.Reports.Format, strings.Join(ReportFormats, ", ")))
+	}
+	if cfg.Reports.Dir == "" {
+		errs = append(errs, fmt.Errorf("reports.dir must not be empty"))
+	}
+	if email := cfg.Reports.Email; email.SMTPAddr != "" {
+		if _, port, err := net.SplitHostPort(email.SMTPAddr); err != nil || port == "" {
+			errs = append(errs, fmt.Errorf("reports.email.smtp_addr: invalid address %q, expected host:port", email.SMTPAddr))
+		}
+		if _, err := mail.ParseAddress(email.From); err != nil {
+			errs = append(errs, fmt.Errorf("reports.email.from: invalid address %q", email.From))
+		}
+		if len(email.To) == 0 {
+			errs = append(errs, fmt.Errorf("reports.email.to must not be empty when reports.email.smtp_addr is set"))
+		}
+		for i, to := range email.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				errs = append(errs, fmt.Errorf("reports.email.to[%d]: invalid address %q", i, to))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// localePattern matches ddragon locales such as "en_US" and "zh_CN"
+var localePattern = regexp.MustCompile(`^[a-z]{2}_[A-Z]{2}$`)
+
+// SummonerIDs returns the op.gg ids of the configured summoners
+func (cfg *Config) SummonerIDs() []string {
+	ids := make([]string, len(cfg.Summoners))
+	for i, s := range cfg.Summoners {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+func validRegion(region string) bool {
+	for _, r := range Regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// validHTTPURL reports whether raw is an absolute http or https URL
+func validHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validSHA256(hash string) bool {
+	if len(hash) != 64 {
+		return false
+	}
+	for _, c := range hash {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
 		}
-		config = cfg
 	}
-	return config
+	return true
 }
 
+// getEnv treats empty variables as unset, docker-compose passes unset variables through as empty strings
 func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
+	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return fallback
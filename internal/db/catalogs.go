@@ -0,0 +1,228 @@
+// internal/db/catalogs.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"opggvisualizer/internal/models"
+	"strconv"
+)
+
+// InsertItem inserts or updates an item in the items table
+func (db *Database) InsertItem(ctx context.Context, itemID string, item models.Item, version string) error {
+	tagsJSON, err := json.Marshal(item.Tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	insertItemSQL := `INSERT INTO items(
+		item_id, name, description, plaintext, gold_base, gold_total, tags, image_url, version,
+		sprite_url, sprite_x, sprite_y, sprite_w, sprite_h
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(item_id) DO UPDATE SET
+		name=excluded.name,
+		description=excluded.description,
+		plaintext=excluded.plaintext,
+		gold_base=excluded.gold_base,
+		gold_total=excluded.gold_total,
+		tags=excluded.tags,
+		image_url=excluded.image_url,
+		version=excluded.version,
+		sprite_url=excluded.sprite_url,
+		sprite_x=excluded.sprite_x,
+		sprite_y=excluded.sprite_y,
+		sprite_w=excluded.sprite_w,
+		sprite_h=excluded.sprite_h;`
+
+	_, err = db.Conn.ExecContext(ctx, insertItemSQL,
+		itemID,
+		item.Name,
+		item.Description,
+		item.Plaintext,
+		int(item.Gold.Base),
+		int(item.Gold.Total),
+		string(tagsJSON),
+		item.Image.VersionedURL(version),
+		version,
+		item.Image.SpriteURL(version),
+		int(item.Image.X),
+		int(item.Image.Y),
+		int(item.Image.W),
+		int(item.Image.H),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert item: %w", err)
+	}
+	return nil
+}
+
+// InsertRuneTree inserts or updates a rune tree and all of its runes in the runes table
+func (db *Database) InsertRuneTree(ctx context.Context, tree models.RuneTree, version string) error {
+	insertRuneSQL := `INSERT INTO runes(
+		rune_id, key, name, tree_id, slot, short_desc, icon_url, version
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(rune_id) DO UPDATE SET
+		key=excluded.key,
+		name=excluded.name,
+		tree_id=excluded.tree_id,
+		slot=excluded.slot,
+		short_desc=excluded.short_desc,
+		icon_url=excluded.icon_url,
+		version=excluded.version;`
+
+	if _, err := db.Conn.ExecContext(ctx, insertRuneSQL,
+		tree.ID, tree.Key, tree.Name, tree.ID, -1, "", models.RuneIconURL(tree.Icon), version,
+	); err != nil {
+		return fmt.Errorf("failed to insert rune tree: %w", err)
+	}
+
+	for slot, runeSlot := range tree.Slots {
+		for _, r := range runeSlot.Runes {
+			if _, err := db.Conn.ExecContext(ctx, insertRuneSQL,
+				r.ID, r.Key, r.Name, tree.ID, slot, r.ShortDesc, models.RuneIconURL(r.Icon), version,
+			); err != nil {
+				return fmt.Errorf("failed to insert rune %s: %w", r.Key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// InsertSummonerSpell inserts or updates a summoner spell in the summoner_spells table
+func (db *Database) InsertSummonerSpell(ctx context.Context, spell models.SummonerSpell, version string) error {
+	spellID, err := strconv.Atoi(spell.Key)
+	if err != nil {
+		return fmt.Errorf("invalid summoner spell key %q: %w", spell.Key, err)
+	}
+
+	var cooldown float64
+	if len(spell.Cooldown) > 0 {
+		cooldown = spell.Cooldown[0]
+	}
+
+	insertSpellSQL := `INSERT INTO summoner_spells(
+		spell_id, key, name, description, cooldown, image_url, version,
+		sprite_url, sprite_x, sprite_y, sprite_w, sprite_h
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(spell_id) DO UPDATE SET
+		key=excluded.key,
+		name=excluded.name,
+		description=excluded.description,
+		cooldown=excluded.cooldown,
+		image_url=excluded.image_url,
+		version=excluded.version,
+		sprite_url=excluded.sprite_url,
+		sprite_x=excluded.sprite_x,
+		sprite_y=excluded.sprite_y,
+		sprite_w=excluded.sprite_w,
+		sprite_h=excluded.sprite_h;`
+
+	_, err = db.Conn.ExecContext(ctx, insertSpellSQL,
+		spellID,
+		spell.ID,
+		spell.Name,
+		spell.Description,
+		cooldown,
+		spell.Image.VersionedURL(version),
+		version,
+		spell.Image.SpriteURL(version),
+		int(spell.Image.X),
+		int(spell.Image.Y),
+		int(spell.Image.W),
+		int(spell.Image.H),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert summoner spell: %w", err)
+	}
+	return nil
+}
+
+// ListItems returns all items ordered by id
+func (db *Database) ListItems(ctx context.Context) ([]models.ItemEntry, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT item_id, name, plaintext, gold_total, tags, image_url FROM items ORDER BY item_id;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ItemEntry{}
+	for rows.Next() {
+		var item models.ItemEntry
+		var tags string
+		if err := rows.Scan(&item.ID, &item.Name, &item.Plaintext, &item.GoldTotal, &tags, &item.ImageURL); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(tags), &item.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags of item %d: %w", item.ID, err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ListRunes returns all rune trees and runes ordered by tree and slot
+func (db *Database) ListRunes(ctx context.Context) ([]models.RuneEntry, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT rune_id, key, name, tree_id, slot, icon_url FROM runes ORDER BY tree_id, slot, rune_id;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runes: %w", err)
+	}
+	defer rows.Close()
+
+	runes := []models.RuneEntry{}
+	for rows.Next() {
+		var r models.RuneEntry
+		if err := rows.Scan(&r.ID, &r.Key, &r.Name, &r.TreeID, &r.Slot, &r.IconURL); err != nil {
+			return nil, err
+		}
+		runes = append(runes, r)
+	}
+	return runes, rows.Err()
+}
+
+// ListSummonerSpells returns all summoner spells ordered by id
+func (db *Database) ListSummonerSpells(ctx context.Context) ([]models.SummonerSpellEntry, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT spell_id, key, name, cooldown, image_url FROM summoner_spells ORDER BY spell_id;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list summoner spells: %w", err)
+	}
+	defer rows.Close()
+
+	spells := []models.SummonerSpellEntry{}
+	for rows.Next() {
+		var spell models.SummonerSpellEntry
+		if err := rows.Scan(&spell.ID, &spell.Key, &spell.Name, &spell.Cooldown, &spell.ImageURL); err != nil {
+			return nil, err
+		}
+		spells = append(spells, spell)
+	}
+	return spells, rows.Err()
+}
+
+// ListImageURLs returns the image and sprite sheet URLs of the current champions, items, runes and summoner spells
+func (db *Database) ListImageURLs(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT image_url FROM champions
+	UNION SELECT image_url FROM items
+	UNION SELECT icon_url FROM runes
+	UNION SELECT image_url FROM summoner_spells
+	UNION SELECT sprite_url FROM champions
+	UNION SELECT sprite_url FROM items
+	UNION SELECT sprite_url FROM summoner_spells;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list image URLs: %w", err)
+	}
+	defer rows.Close()
+
+	urls := []string{}
+	for rows.Next() {
+		var url sql.NullString
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		if url.Valid && url.String != "" {
+			urls = append(urls, url.String)
+		}
+	}
+	return urls, rows.Err()
+}
@@ -2,6 +2,7 @@
 package db
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"opggvisualizer/internal/models"
@@ -10,7 +11,7 @@ import (
 )
 
 // InsertChampion inserts a champion into the champions table
-func (db *Database) InsertChampion(champion models.Champion) error {
+func (db *Database) InsertChampion(ctx context.Context, champion models.Champion) error {
 	tagsJSON, err := json.Marshal(champion.Tags)
 	if err != nil {
 		return fmt.Errorf("failed to marshal tags: %w", err)
@@ -23,8 +24,9 @@ func (db *Database) InsertChampion(champion models.Champion) error {
 
 	insertChampionSQL := `INSERT INTO champions(
 		champion_id, name, title, tags, type, format, blurb, partype,
-		attack, defense, magic, difficulty, stats, image_url
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		attack, defense, magic, difficulty, stats, image_url,
+		sprite_url, sprite_x, sprite_y, sprite_w, sprite_h
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	ON CONFLICT(champion_id) DO UPDATE SET
 		name=excluded.name,
 		title=excluded.title,
@@ -38,10 +40,15 @@ func (db *Database) InsertChampion(champion models.Champion) error {
 		magic=excluded.magic,
 		difficulty=excluded.difficulty,
 		stats=excluded.stats,
-		image_url=excluded.image_url;`
+		image_url=excluded.image_url,
+		sprite_url=excluded.sprite_url,
+		sprite_x=excluded.sprite_x,
+		sprite_y=excluded.sprite_y,
+		sprite_w=excluded.sprite_w,
+		sprite_h=excluded.sprite_h;`
 
 	// Use champion.Key as champion_id to match participant's champion_id
-	_, err = db.Conn.Exec(insertChampionSQL,
+	_, err = db.Conn.ExecContext(ctx, insertChampionSQL,
 		champion.Key, // Changed from champion.ID to champion.Key
 		champion.Name,
 		champion.Title,
@@ -56,6 +63,11 @@ func (db *Database) InsertChampion(champion models.Champion) error {
 		champion.Info.Difficulty,
 		string(statsJSON),
 		champion.Image.FullURL(),
+		champion.Image.SpriteURL(champion.Image.Version),
+		int(champion.Image.X),
+		int(champion.Image.Y),
+		int(champion.Image.W),
+		int(champion.Image.H),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert champion: %w", err)
@@ -63,9 +75,25 @@ func (db *Database) InsertChampion(champion models.Champion) error {
 	return nil
 }
 
+// InsertChampionTranslation stores the name, title and blurb of a champion in a locale
+func (db *Database) InsertChampionTranslation(ctx context.Context, champion models.Champion, locale string) error {
+	_, err := db.Conn.ExecContext(ctx, `INSERT INTO champion_translations(champion_id, locale, name, title, blurb)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(champion_id, locale) DO UPDATE SET
+		name=excluded.name,
+		title=excluded.title,
+		blurb=excluded.blurb;`,
+		champion.Key, locale, champion.Name, champion.Title, champion.Blurb,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert champion translation: %w", err)
+	}
+	return nil
+}
+
 // Function to list all champion_ids
-func (db *Database) ListChampionIDs() ([]string, error) {
-	rows, err := db.Conn.Query("SELECT champion_id FROM champions;")
+func (db *Database) ListChampionIDs(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(ctx, "SELECT champion_id FROM champions;")
 	if err != nil {
 		return nil, err
 	}
@@ -82,15 +110,43 @@ func (db *Database) ListChampionIDs() ([]string, error) {
 	return championIDs, nil
 }
 
-// ClearChampionData clears all data from the champions table
-func (db *Database) ClearChampionData() error {
-	_, err := db.Conn.Exec(`DELETE FROM champions;`)
+// ListChampions returns all champions ordered by name. Names and titles are translated into
+// locale where a translation is stored, otherwise the default locale is returned.
+func (db *Database) ListChampions(ctx context.Context, locale string) ([]models.ChampionEntry, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT c.champion_id, COALESCE(t.name, c.name) AS name, COALESCE(t.title, c.title), c.tags, c.image_url
+	FROM champions c
+	LEFT JOIN champion_translations t ON t.champion_id = c.champion_id AND t.locale = ?
+	ORDER BY name;`, locale)
 	if err != nil {
-		return fmt.Errorf("failed to clear champion data: %w", err)
+		return nil, fmt.Errorf("failed to list champions: %w", err)
+	}
+	defer rows.Close()
+
+	champions := []models.ChampionEntry{}
+	for rows.Next() {
+		var champion models.ChampionEntry
+		var tags string
+		if err := rows.Scan(&champion.ID, &champion.Name, &champion.Title, &tags, &champion.ImageURL); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(tags), &champion.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags of champion %d: %w", champion.ID, err)
+		}
+		champions = append(champions, champion)
+	}
+	return champions, rows.Err()
+}
+
+// ClearChampionData clears all data from the champions and champion_translations tables, the item, rune and summoner spell catalogs and their version history
+func (db *Database) ClearChampionData(ctx context.Context) error {
+	for _, table := range []string{"champion_translations", "champions", "items", "runes", "summoner_spells", "champion_versions", "item_versions", "static_versions"} {
+		if _, err := db.Conn.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s;`, table)); err != nil {
+			return fmt.Errorf("failed to clear data from table %s: %w", table, err)
+		}
 	}
 
 	// Wipe last fetch time for champions
-	_, err = db.Conn.Exec(`UPDATE fetch SET last_fetch = NULL WHERE fetch_type = "CHAMPIONS";`)
+	_, err := db.Conn.ExecContext(ctx, `UPDATE fetch SET last_fetch = NULL WHERE fetch_type = "CHAMPIONS";`)
 	if err != nil {
 		return fmt.Errorf("failed to clear last fetch time for champions: %w", err)
 	}
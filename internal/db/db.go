@@ -2,36 +2,34 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"opggvisualizer/internal/config"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-var db *Database // Constant to hold the DB connection
-
 type Database struct {
 	Conn *sql.DB // At some point in the future we might want to make this an array
 }
 
-func newDatabase(dbPath string) (*Database, error) {
+// Open connects to the SQLite database at dbPath and creates the schema if necessary
+func Open(ctx context.Context, dbPath string) (*Database, error) {
 	conn, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	db = &Database{Conn: conn}
+	db := &Database{Conn: conn}
 
 	// Enable foreign key constraints
-	if _, err := db.Conn.Exec(`PRAGMA foreign_keys = ON;`); err != nil {
+	if _, err := db.Conn.ExecContext(ctx, `PRAGMA foreign_keys = ON;`); err != nil {
 		conn.Close()
 		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
 	}
 
-	if err := db.initializeSchema(); err != nil {
+	if err := db.initializeSchema(ctx); err != nil {
 		conn.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
@@ -39,24 +37,12 @@ func newDatabase(dbPath string) (*Database, error) {
 	return db, nil
 }
 
-func GetDatabaseConnection() *Database {
-	if db == nil {
-		cfg := config.GetConfig()
-		newDb, err := newDatabase(cfg.DatabasePath)
-		if err != nil {
-			log.Fatalf("Error initializing database: %v", err)
-		}
-		db = newDb
-	}
-	return db
-}
-
 func (db *Database) Close() error {
 	return db.Conn.Close()
 }
 
 // Initialize the database schema with enhanced structure
-func (db *Database) initializeSchema() error {
+func (db *Database) initializeSchema(ctx context.Context) error {
 	schemaStatements := []string{
 		// Games Table
 		`CREATE TABLE IF NOT EXISTS games (
@@ -178,26 +164,297 @@ func (db *Database) initializeSchema() error {
 			image_url TEXT
 		);`,
 
+		// Champion Translations Table, names and titles of the current champions in the configured locales
+		`CREATE TABLE IF NOT EXISTS champion_translations (
+			champion_id TEXT,
+			locale TEXT,
+			name TEXT,
+			title TEXT,
+			blurb TEXT,
+			PRIMARY KEY(champion_id, locale),
+			FOREIGN KEY(champion_id) REFERENCES champions(champion_id)
+		);`,
+
+		// Items Table
+		`CREATE TABLE IF NOT EXISTS items (
+			item_id INTEGER PRIMARY KEY,
+			name TEXT,
+			description TEXT,
+			plaintext TEXT,
+			gold_base INTEGER,
+			gold_total INTEGER,
+			tags TEXT, -- Serialized JSON array
+			image_url TEXT,
+			version TEXT
+		);`,
+
+		// Runes Table, holds both rune trees and the runes in them
+		`CREATE TABLE IF NOT EXISTS runes (
+			rune_id INTEGER PRIMARY KEY,
+			key TEXT,
+			name TEXT,
+			tree_id INTEGER, -- Equal to rune_id for trees
+			slot INTEGER, -- -1 for trees, 0 for keystones
+			short_desc TEXT,
+			icon_url TEXT,
+			version TEXT
+		);`,
+
+		// Summoner Spells Table
+		`CREATE TABLE IF NOT EXISTS summoner_spells (
+			spell_id INTEGER PRIMARY KEY,
+			key TEXT,
+			name TEXT,
+			description TEXT,
+			cooldown REAL,
+			image_url TEXT,
+			version TEXT
+		);`,
+
+		// Champion Versions Table, one row per champion and ddragon version
+		`CREATE TABLE IF NOT EXISTS champion_versions (
+			champion_id TEXT,
+			version TEXT,
+			name TEXT,
+			title TEXT,
+			tags TEXT, -- Serialized JSON array
+			type TEXT,
+			format TEXT,
+			blurb TEXT,
+			partype TEXT,
+			attack REAL,
+			defense REAL,
+			magic REAL,
+			difficulty REAL,
+			stats TEXT, -- Serialized JSON object
+			image_url TEXT,
+			PRIMARY KEY(champion_id, version)
+		);`,
+
+		// Item Versions Table, one row per item and ddragon version
+		`CREATE TABLE IF NOT EXISTS item_versions (
+			item_id INTEGER,
+			version TEXT,
+			name TEXT,
+			description TEXT,
+			plaintext TEXT,
+			gold_base INTEGER,
+			gold_total INTEGER,
+			tags TEXT, -- Serialized JSON array
+			image_url TEXT,
+			PRIMARY KEY(item_id, version)
+		);`,
+
+		// Static Versions Table, the ddragon versions stored in champion_versions and item_versions
+		`CREATE TABLE IF NOT EXISTS static_versions (
+			version TEXT PRIMARY KEY,
+			patch TEXT, -- major.minor, matches games.patch
+			fetched_at TEXT
+		);`,
+
+		// API Tokens Table
+		`CREATE TABLE IF NOT EXISTS api_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			sha256 TEXT NOT NULL UNIQUE, -- Hex encoded SHA-256 of the token
+			created_at TEXT,
+			revoked_at TEXT
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS api_tokens_active_name ON api_tokens(name) WHERE revoked_at IS NULL;`,
+
+		// Goals Table, progress is updated after each games fetch
+		`CREATE TABLE IF NOT EXISTS goals (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			summoner_id TEXT NOT NULL,
+			metric TEXT NOT NULL,
+			comparison TEXT NOT NULL, -- >=, >, <= or <
+			target REAL NOT NULL,
+			share REAL, -- Share of games that must meet the target, NULL compares the average
+			position TEXT,
+			period TEXT NOT NULL, -- week, month or all
+			created_at TEXT,
+			evaluated_at TEXT,
+			games INTEGER,
+			value REAL,
+			met BOOLEAN
+		);`,
+
 		// Fetch Table
 		`CREATE TABLE IF NOT EXISTS fetch (
-			fetch_type TEXT PRIMARY KEY, -- Type of fetch (CHAMPIONS, GAMES)
+			fetch_type TEXT PRIMARY KEY, -- Type of fetch (CHAMPIONS, GAMES:<region>:<summoner id>)
 			last_fetch TEXT -- Last fetch timestamp
 		);`,
 	}
 
 	for _, stmt := range schemaStatements {
-		if _, err := db.Conn.Exec(stmt); err != nil {
+		if _, err := db.Conn.ExecContext(ctx, stmt); err != nil {
 			return fmt.Errorf("failed to execute schema statement: %w", err)
 		}
 	}
 
+	return db.migrate(ctx)
+}
+
+// migrations change tables created by earlier releases. They run once, in order, after the
+// base schema. PRAGMA user_version records how many have been applied. Only ever append.
+// Migrations only change the schema: the tables derived from the stored games are filled again by
+// migrate once the last migration is applied, with the formulas of the current release.
+var migrations = [][]string{
+	// 1: Link games to the static data of their patch
+	{
+		`ALTER TABLE games ADD COLUMN patch TEXT;`,
+		`UPDATE games SET patch = CASE
+			WHEN instr(substr(version, instr(version, '.') + 1), '.') > 0
+			THEN substr(version, 1, instr(version, '.') + instr(substr(version, instr(version, '.') + 1), '.') - 1)
+			ELSE version
+		END;`,
+		`CREATE VIEW IF NOT EXISTS game_static_versions AS
+		SELECT games.game_id, MAX(static_versions.version) AS static_version
+		FROM games
+		JOIN static_versions ON static_versions.patch = games.patch
+		GROUP BY games.game_id;`,
+	},
+	// 2: Locate champion, item and summoner spell images in the ddragon sprite sheets
+	{
+		`ALTER TABLE champions ADD COLUMN sprite_url TEXT;`,
+		`ALTER TABLE champions ADD COLUMN sprite_x INTEGER;`,
+		`ALTER TABLE champions ADD COLUMN sprite_y INTEGER;`,
+		`ALTER TABLE champions ADD COLUMN sprite_w INTEGER;`,
+		`ALTER TABLE champions ADD COLUMN sprite_h INTEGER;`,
+		`ALTER TABLE items ADD COLUMN sprite_url TEXT;`,
+		`ALTER TABLE items ADD COLUMN sprite_x INTEGER;`,
+		`ALTER TABLE items ADD COLUMN sprite_y INTEGER;`,
+		`ALTER TABLE items ADD COLUMN sprite_w INTEGER;`,
+		`ALTER TABLE items ADD COLUMN sprite_h INTEGER;`,
+		`ALTER TABLE summoner_spells ADD COLUMN sprite_url TEXT;`,
+		`ALTER TABLE summoner_spells ADD COLUMN sprite_x INTEGER;`,
+		`ALTER TABLE summoner_spells ADD COLUMN sprite_y INTEGER;`,
+		`ALTER TABLE summoner_spells ADD COLUMN sprite_w INTEGER;`,
+		`ALTER TABLE summoner_spells ADD COLUMN sprite_h INTEGER;`,
+	},
+	// 3: Identify participants by op.gg summoner id and record their creep score.
+	// Rows stored earlier keep NULL, the summoner is still known by name.
+	{
+		`ALTER TABLE participants ADD COLUMN summoner_id TEXT;`,
+		`ALTER TABLE participants ADD COLUMN minion_kill INTEGER;`,
+		`ALTER TABLE participants ADD COLUMN neutral_minion_kill INTEGER;`,
+		`CREATE INDEX IF NOT EXISTS participants_summoner_id ON participants(summoner_id);`,
+	},
+	// 4: Store bans as integer champion keys with their pick order. Missed bans keep a NULL champion_id.
+	{
+		`CREATE TABLE team_banned_champions_v2 (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			team_id INTEGER,
+			champion_id INTEGER,
+			pick_order INTEGER, -- 1 to 5 within the team
+			FOREIGN KEY(team_id) REFERENCES teams(team_id)
+		);`,
+		`INSERT INTO team_banned_champions_v2 (id, team_id, champion_id, pick_order)
+		SELECT
+			id,
+			team_id,
+			CASE WHEN banned_champion_id > 0 THEN CAST(banned_champion_id AS INTEGER) END,
+			ROW_NUMBER() OVER (PARTITION BY team_id ORDER BY id)
+		FROM team_banned_champions;`,
+		`DROP TABLE team_banned_champions;`,
+		`ALTER TABLE team_banned_champions_v2 RENAME TO team_banned_champions;`,
+		`CREATE VIEW IF NOT EXISTS game_bans AS
+		SELECT teams.game_id, teams.key AS team_key, teams.is_win, bans.pick_order, bans.champion_id, champions.name AS champion_name
+		FROM team_banned_champions bans
+		JOIN teams ON teams.team_id = bans.team_id
+		LEFT JOIN champions ON champions.champion_id = CAST(bans.champion_id AS TEXT)
+		WHERE bans.champion_id IS NOT NULL;`,
+	},
+	// 5: Materialize metrics derived from each participant and their team, see metrics.go
+	{
+		`CREATE TABLE participant_metrics (
+			participant_id INTEGER PRIMARY KEY,
+			game_id TEXT,
+			kda REAL,                -- (kills + assists) / deaths, deaths counted as at least 1
+			kill_participation REAL, -- (kills + assists) / team kills, 0 to 1
+			damage_share REAL,       -- Share of the team's damage to champions, 0 to 1
+			gold_share REAL,         -- Share of the team's gold, 0 to 1
+			cs_per_minute REAL,      -- NULL for games stored before creep score was recorded
+			vision_per_minute REAL,
+			damage_per_gold REAL,
+			op_score REAL,           -- 10 - op_score_rank, higher is better
+			FOREIGN KEY(participant_id) REFERENCES participants(id),
+			FOREIGN KEY(game_id) REFERENCES games(game_id)
+		);`,
+		`CREATE INDEX IF NOT EXISTS participant_metrics_game_id ON participant_metrics(game_id);`,
+	},
+	// 6: Flag remakes and broken games, see flags.go
+	{
+		`CREATE TABLE game_flags (
+			game_id TEXT,
+			flag TEXT, -- One of models.GameFlags
+			PRIMARY KEY(game_id, flag),
+			FOREIGN KEY(game_id) REFERENCES games(game_id)
+		);`,
+	},
+	// 7: Store gold and damage per minute with the other participant metrics
+	{
+		`ALTER TABLE participant_metrics ADD COLUMN gold_per_minute REAL;`,
+		`ALTER TABLE participant_metrics ADD COLUMN damage_per_minute REAL;`,
+	},
+	// 8: Link games to the newest version of their patch by number, MAX compared the versions as text (14.20.9 > 14.20.10)
+	{
+		`DROP VIEW IF EXISTS game_static_versions;`,
+		`CREATE VIEW game_static_versions AS
+		SELECT games.game_id, static_versions.version AS static_version
+		FROM games
+		JOIN static_versions ON static_versions.version = (
+			SELECT newest.version FROM static_versions newest
+			WHERE newest.patch = games.patch
+			ORDER BY CAST(substr(newest.version, length(newest.patch) + 2) AS INTEGER) DESC
+			LIMIT 1
+		);`,
+	},
+}
+
+func (db *Database) migrate(ctx context.Context) error {
+	var applied int
+	if err := db.Conn.QueryRowContext(ctx, `PRAGMA user_version;`).Scan(&applied); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := applied; i < len(migrations); i++ {
+		tx, err := db.Conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", i+1, err)
+		}
+		for _, stmt := range migrations[i] {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+			}
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d;`, i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+	}
+
+	// Derive the metrics and flags of the stored games in the schema the migrations left
+	if applied < len(migrations) {
+		if _, err := db.RecomputeParticipantMetrics(ctx); err != nil {
+			return err
+		}
+		if _, err := db.ClassifyGames(ctx); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// GetLastFetch returns the last fetch timestamp for fetchType="CHAMPIONS" or "GAMES"
-func (db *Database) GetLastFetch(fetchType string) (time.Time, error) {
+// GetLastFetch returns the last fetch timestamp for fetchType="CHAMPIONS" or "GAMES:<region>:<summoner id>"
+func (db *Database) GetLastFetch(ctx context.Context, fetchType string) (time.Time, error) {
 	var lastFetch string
-	err := db.Conn.QueryRow("SELECT last_fetch FROM fetch WHERE fetch_type = ?;", fetchType).Scan(&lastFetch)
+	err := db.Conn.QueryRowContext(ctx, "SELECT last_fetch FROM fetch WHERE fetch_type = ?;", fetchType).Scan(&lastFetch)
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -210,8 +467,8 @@ func (db *Database) GetLastFetch(fetchType string) (time.Time, error) {
 	return lastFetchTime, nil
 }
 
-// SetLastFetch sets the last fetch timestamp for fetchType="CHAMPIONS" or "GAMES"
-func (db *Database) SetLastFetch(fetchType string, lastFetch time.Time) error {
-	_, err := db.Conn.Exec("INSERT OR REPLACE INTO fetch (fetch_type, last_fetch) VALUES (?, ?);", fetchType, lastFetch.Format(time.RFC3339))
+// SetLastFetch sets the last fetch timestamp for fetchType="CHAMPIONS" or "GAMES:<region>:<summoner id>"
+func (db *Database) SetLastFetch(ctx context.Context, fetchType string, lastFetch time.Time) error {
+	_, err := db.Conn.ExecContext(ctx, "INSERT OR REPLACE INTO fetch (fetch_type, last_fetch) VALUES (?, ?);", fetchType, lastFetch.Format(time.RFC3339))
 	return err
 }
@@ -0,0 +1,104 @@
+// internal/db/flags.go
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"opggvisualizer/internal/models"
+)
+
+// Classifier thresholds
+const (
+	earlySurrenderLength = 20 * 60 // Seconds, the earliest a surrender vote can pass is 15 minutes
+	leaverGoldRatio      = 0.4     // Of the average gold of the participant's team
+)
+
+// selectGameFlags selects the game_id and flag of every flag raised on the games g matching condition.
+// Remakes get no other flag than remake and incomplete data.
+func selectGameFlags(condition string) string {
+	return fmt.Sprintf(`SELECT g.game_id, '%[2]s' FROM games g
+	WHERE %[1]s AND g.is_remake
+	UNION
+	SELECT g.game_id, '%[3]s' FROM games g
+	WHERE %[1]s AND NOT g.is_remake AND g.game_length < %[6]d
+	UNION
+	SELECT DISTINCT g.game_id, '%[4]s' FROM games g
+	JOIN participants p ON p.game_id = g.game_id
+	JOIN teams t ON t.game_id = p.game_id AND t.key = p.team_key
+	WHERE %[1]s AND NOT g.is_remake AND p.gold_earned * 5 < t.gold_earned * %[7]g
+	UNION
+	SELECT g.game_id, '%[5]s' FROM games g
+	WHERE %[1]s AND (
+		g.game_length IS NULL OR g.game_length <= 0
+		OR (SELECT COUNT(*) FROM teams WHERE teams.game_id = g.game_id) <> 2
+		OR (SELECT COUNT(*) FROM participants WHERE participants.game_id = g.game_id) <> 10
+	)`,
+		condition, models.FlagRemake, models.FlagEarlySurrender, models.FlagLeaverSuspected, models.FlagIncompleteData,
+		earlySurrenderLength, leaverGoldRatio)
+}
+
+// ClassifyGame sets the flags of one game. Call it once the game's teams and participants are stored.
+func (db *Database) ClassifyGame(ctx context.Context, gameID string) error {
+	tx, err := db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM game_flags WHERE game_id = ?;`, gameID); err != nil {
+		return fmt.Errorf("failed to clear flags of game %s: %w", gameID, err)
+	}
+	// The condition appears once per part of the union
+	query := `INSERT INTO game_flags (game_id, flag) ` + selectGameFlags("g.game_id = ?") + `;`
+	if _, err := tx.ExecContext(ctx, query, gameID, gameID, gameID, gameID); err != nil {
+		return fmt.Errorf("failed to classify game %s: %w", gameID, err)
+	}
+	return tx.Commit()
+}
+
+// ClassifyGames sets the flags of every stored game again, e.g. after the classifier changed, and
+// returns the number of games per flag
+func (db *Database) ClassifyGames(ctx context.Context) (map[string]int, error) {
+	tx, err := db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM game_flags;`); err != nil {
+		return nil, fmt.Errorf("failed to clear game flags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO game_flags (game_id, flag) `+selectGameFlags("1")+`;`); err != nil {
+		return nil, fmt.Errorf("failed to classify games: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	rows, err := db.Conn.QueryContext(ctx, `SELECT flag, COUNT(*) FROM game_flags GROUP BY flag;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count game flags: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var flag string
+		var count int
+		if err := rows.Scan(&flag, &count); err != nil {
+			return nil, err
+		}
+		counts[flag] = count
+	}
+	return counts, rows.Err()
+}
+
+// splitFlags splits the flags of a game concatenated by GROUP_CONCAT
+func splitFlags(concatenated string) []string {
+	if concatenated == "" {
+		return []string{}
+	}
+	return strings.Split(concatenated, ",")
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"opggvisualizer/internal/models"
+)
+
+// storeTestGame stores a game of gameLength seconds with players participants split over two teams, each earning
+// 10000 gold except for the participants listed in gold
+func storeTestGame(t *testing.T, database *Database, id string, gameLength int, remake bool, players int, gold map[int]int) {
+	t.Helper()
+	ctx := context.Background()
+	game := models.Game{ID: id, CreatedAt: time.Date(2024, 11, 4, 18, 0, 0, 0, time.UTC), GameLengthSecond: gameLength, IsRemake: remake, Version: "14.20.1"}
+	if err := database.InsertGame(ctx, game); err != nil {
+		t.Fatal(err)
+	}
+
+	teamGold := map[string]int{}
+	participants := make([]models.Participant, players)
+	for i := range participants {
+		p := models.Participant{ParticipantID: i + 1, ChampionID: 103, TeamKey: "BLUE", IsRemake: remake}
+		if i >= 5 {
+			p.TeamKey = "RED"
+		}
+		p.Summoner.Name = fmt.Sprintf("player %d", i+1)
+		p.Stats.GoldEarned = 10000
+		if g, ok := gold[i+1]; ok {
+			p.Stats.GoldEarned = float64(g)
+		}
+		teamGold[p.TeamKey] += int(p.Stats.GoldEarned)
+		participants[i] = p
+	}
+	for _, key := range []string{"BLUE", "RED"} {
+		team := models.Team{Key: key, GameStat: models.TeamStat{IsWin: key == "BLUE", IsRemake: remake, GoldEarned: teamGold[key]}}
+		if err := database.InsertTeam(ctx, id, team); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range participants {
+		if err := database.InsertParticipant(ctx, id, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClassifyGames(t *testing.T) {
+	ctx := context.Background()
+	database, err := Open(ctx, filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.InsertChampion(ctx, models.Champion{ID: "Ahri", Key: "103", Name: "Ahri"}); err != nil {
+		t.Fatal(err)
+	}
+
+	storeTestGame(t, database, "clean", 1800, false, 10, nil)
+	storeTestGame(t, database, "remake", 200, true, 10, map[int]int{1: 100})
+	storeTestGame(t, database, "short", 1100, false, 10, nil)
+	storeTestGame(t, database, "leaver", 1800, false, 10, map[int]int{3: 1500})
+	storeTestGame(t, database, "nine", 1800, false, 9, nil)
+
+	counts, err := database.ClassifyGames(ctx)
+	if err != nil {
+		t.Fatalf("ClassifyGames: %v", err)
+	}
+	wantCounts := map[string]int{
+		models.FlagRemake:          1,
+		models.FlagEarlySurrender:  1,
+		models.FlagLeaverSuspected: 1,
+		models.FlagIncompleteData:  1,
+	}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("ClassifyGames counts = %v, want %v", counts, wantCounts)
+	}
+
+	// A remake is not also an early surrender, nor a leaver for its low gold
+	want := map[string][]string{
+		"clean":  nil,
+		"remake": {models.FlagRemake},
+		"short":  {models.FlagEarlySurrender},
+		"leaver": {models.FlagLeaverSuspected},
+		"nine":   {models.FlagIncompleteData},
+	}
+	for id, wantFlags := range want {
+		rows, err := database.Conn.QueryContext(ctx, `SELECT flag FROM game_flags WHERE game_id = ? ORDER BY flag;`, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var flags []string
+		for rows.Next() {
+			var flag string
+			if err := rows.Scan(&flag); err != nil {
+				t.Fatal(err)
+			}
+			flags = append(flags, flag)
+		}
+		rows.Close()
+		if !reflect.DeepEqual(flags, wantFlags) {
+			t.Errorf("flags of game %s = %v, want %v", id, flags, wantFlags)
+		}
+	}
+}
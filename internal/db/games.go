@@ -2,6 +2,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"opggvisualizer/internal/models"
@@ -12,18 +13,18 @@ import (
 )
 
 // Insert a game into the games table
-func (db *Database) InsertGame(game models.Game) error {
+func (db *Database) InsertGame(ctx context.Context, game models.Game) error {
 	insertGameSQL := `INSERT INTO games(
 		game_id, created_at, game_length, tier, division, tier_image_url, border_image_url,
 		is_remake, meta_version, game_type, is_opscore_active, is_recorded, version,
-		first_game_created_at, last_game_created_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+		first_game_created_at, last_game_created_at, patch
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
 
 	createdAt := game.CreatedAt.Format(time.RFC3339)
 	firstGameCreatedAt := game.Meta.FirstGameCreatedAt.Format(time.RFC3339)
 	lastGameCreatedAt := game.Meta.LastGameCreatedAt.Format(time.RFC3339)
 
-	_, err := db.Conn.Exec(insertGameSQL,
+	_, err := db.Conn.ExecContext(ctx, insertGameSQL,
 		game.ID,
 		createdAt,
 		game.GameLengthSecond,
@@ -39,6 +40,7 @@ func (db *Database) InsertGame(game models.Game) error {
 		game.Version,
 		firstGameCreatedAt,
 		lastGameCreatedAt,
+		game.Patch(),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert game: %w", err)
@@ -47,7 +49,7 @@ func (db *Database) InsertGame(game models.Game) error {
 }
 
 // Insert a team into the teams table
-func (db *Database) InsertTeam(gameID string, team models.Team) error {
+func (db *Database) InsertTeam(ctx context.Context, gameID string, team models.Team) error {
 	insertTeamSQL := `INSERT INTO teams(
 		game_id, key, is_win, champion_first, inhibitor_first, rift_herald_first, death,
 		champion_kill, inhibitor_kill, dragon_first, horde_first, rift_herald_kill,
@@ -55,7 +57,7 @@ func (db *Database) InsertTeam(gameID string, team models.Team) error {
 		baron_kill, baron_first, tower_kill
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
 
-	result, err := db.Conn.Exec(insertTeamSQL,
+	result, err := db.Conn.ExecContext(ctx, insertTeamSQL,
 		gameID,
 		team.Key,
 		team.GameStat.IsWin,
@@ -88,9 +90,9 @@ func (db *Database) InsertTeam(gameID string, team models.Team) error {
 		return fmt.Errorf("failed to retrieve last insert ID for team: %w", err)
 	}
 
-	// Insert banned champions
-	for _, bannedChamp := range team.BannedChampions {
-		if err := db.InsertTeamBannedChampion(int(teamID), bannedChamp); err != nil {
+	// Insert banned champions, op.gg lists them in ban order
+	for i, bannedChamp := range team.BannedChampions {
+		if err := db.InsertTeamBannedChampion(ctx, int(teamID), int(bannedChamp), i+1); err != nil {
 			log.Printf("Error inserting banned champion %v for team %d: %v", bannedChamp, teamID, err)
 			continue
 		}
@@ -99,15 +101,21 @@ func (db *Database) InsertTeam(gameID string, team models.Team) error {
 	return nil
 }
 
-// InsertTeamBannedChampion inserts a banned champion into the team_banned_champions table
-func (db *Database) InsertTeamBannedChampion(teamID int, bannedChampion float64) error {
+// InsertTeamBannedChampion inserts a banned champion into the team_banned_champions table.
+// A missed ban, reported by op.gg as -1, is stored with a NULL champion_id to keep the pick order.
+func (db *Database) InsertTeamBannedChampion(ctx context.Context, teamID int, championID int, pickOrder int) error {
 	insertBannedChampionSQL := `INSERT INTO team_banned_champions(
-		team_id, banned_champion_id
-	) VALUES (?, ?);`
+		team_id, champion_id, pick_order
+	) VALUES (?, ?, ?);`
 
-	_, err := db.Conn.Exec(insertBannedChampionSQL,
+	var champion any
+	if championID > 0 {
+		champion = championID
+	}
+	_, err := db.Conn.ExecContext(ctx, insertBannedChampionSQL,
 		teamID,
-		bannedChampion,
+		champion,
+		pickOrder,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert banned champion: %w", err)
@@ -116,19 +124,19 @@ func (db *Database) InsertTeamBannedChampion(teamID int, bannedChampion float64)
 }
 
 // InsertParticipant inserts a participant into the participants table
-func (db *Database) InsertParticipant(gameID string, participant models.Participant) error {
+func (db *Database) InsertParticipant(ctx context.Context, gameID string, participant models.Participant) error {
 	insertParticipantSQL := `INSERT INTO participants(
 		game_id, participant_id, summoner_name, champion_id, position, role, kills,
 		deaths, assists, gold_earned, damage_dealt, damage_taken, vision_score,
 		primary_rune_id, secondary_rune_page_id, lane_score,
 		team_key, result, ward_place, op_score_rank, barrack_kill, total_heal,
-		game_type, is_remake
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+		game_type, is_remake, summoner_id, minion_kill, neutral_minion_kill
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
 
 	// Convert ChampionID from int to string
 	championIDStr := strconv.Itoa(participant.ChampionID)
 
-	result, err := db.Conn.Exec(insertParticipantSQL,
+	result, err := db.Conn.ExecContext(ctx, insertParticipantSQL,
 		gameID,
 		participant.ParticipantID,
 		participant.Summoner.Name,
@@ -153,6 +161,9 @@ func (db *Database) InsertParticipant(gameID string, participant models.Particip
 		int(participant.Stats.TotalHeal),
 		participant.GameType,
 		participant.IsRemake,
+		participant.Summoner.SummonerID,
+		int(participant.Stats.MinionKill),
+		int(participant.Stats.NeutralMinionKill),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert participant: %w", err)
@@ -165,7 +176,7 @@ func (db *Database) InsertParticipant(gameID string, participant models.Particip
 
 	// Insert items
 	for _, item := range participant.Items {
-		if err := db.InsertParticipantItem(int(participantDBID), int(item)); err != nil {
+		if err := db.InsertParticipantItem(ctx, int(participantDBID), int(item)); err != nil {
 			log.Printf("Error inserting item %v for participant %d: %v", item, participantDBID, err)
 			continue
 		}
@@ -173,7 +184,7 @@ func (db *Database) InsertParticipant(gameID string, participant models.Particip
 
 	// Insert spells
 	for _, spell := range participant.Spells {
-		if err := db.InsertParticipantSpell(int(participantDBID), int(spell)); err != nil {
+		if err := db.InsertParticipantSpell(ctx, int(participantDBID), int(spell)); err != nil {
 			log.Printf("Error inserting spell %v for participant %d: %v", spell, participantDBID, err)
 			continue
 		}
@@ -183,12 +194,12 @@ func (db *Database) InsertParticipant(gameID string, participant models.Particip
 }
 
 // InsertParticipantItem inserts an item into the participant_items table
-func (db *Database) InsertParticipantItem(participantID int, itemID int) error {
+func (db *Database) InsertParticipantItem(ctx context.Context, participantID int, itemID int) error {
 	insertItemSQL := `INSERT INTO participant_items(
 		participant_id, item_id
 	) VALUES (?, ?);`
 
-	_, err := db.Conn.Exec(insertItemSQL,
+	_, err := db.Conn.ExecContext(ctx, insertItemSQL,
 		participantID,
 		itemID,
 	)
@@ -199,12 +210,12 @@ func (db *Database) InsertParticipantItem(participantID int, itemID int) error {
 }
 
 // InsertParticipantSpell inserts a spell into the participant_spells table
-func (db *Database) InsertParticipantSpell(participantID int, spellID int) error {
+func (db *Database) InsertParticipantSpell(ctx context.Context, participantID int, spellID int) error {
 	insertSpellSQL := `INSERT INTO participant_spells(
 		participant_id, spell_id
 	) VALUES (?, ?);`
 
-	_, err := db.Conn.Exec(insertSpellSQL,
+	_, err := db.Conn.ExecContext(ctx, insertSpellSQL,
 		participantID,
 		spellID,
 	)
@@ -215,16 +226,17 @@ func (db *Database) InsertParticipantSpell(participantID int, spellID int) error
 }
 
 // ClearGameData clears all data from the game-related tables
-func (db *Database) ClearGameData() error {
-	tables := []string{"games", "teams", "players", "stats"}
+func (db *Database) ClearGameData(ctx context.Context) error {
+	// Children before parents so foreign keys are never left dangling
+	tables := []string{"participant_items", "participant_spells", "participant_metrics", "participants", "team_banned_champions", "teams", "game_flags", "games"}
 	for _, table := range tables {
-		if _, err := db.Conn.Exec(fmt.Sprintf(`DELETE FROM %s;`, table)); err != nil {
+		if _, err := db.Conn.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s;`, table)); err != nil {
 			return fmt.Errorf("failed to clear data from table %s: %w", table, err)
 		}
 	}
 
 	// Wipe last fetch time for games
-	if _, err := db.Conn.Exec(`UPDATE fetch SET last_fetch = NULL WHERE fetch_type = "GAMES";`); err != nil {
+	if _, err := db.Conn.ExecContext(ctx, `UPDATE fetch SET last_fetch = NULL WHERE fetch_type LIKE "GAMES%";`); err != nil {
 		return fmt.Errorf("failed to clear last fetch time for games: %w", err)
 	}
 	return nil
@@ -0,0 +1,110 @@
+// internal/db/goals.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"opggvisualizer/internal/models"
+)
+
+// InsertGoal stores a new goal and returns its id
+func (db *Database) InsertGoal(ctx context.Context, goal models.Goal) (int64, error) {
+	var share any
+	if goal.Share > 0 {
+		share = goal.Share
+	}
+	var position any
+	if goal.Position != "" {
+		position = goal.Position
+	}
+	result, err := db.Conn.ExecContext(ctx, `INSERT INTO goals(
+		summoner_id, metric, comparison, target, share, position, period, created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+		goal.SummonerID,
+		goal.Metric,
+		goal.Comparison,
+		goal.Target,
+		share,
+		position,
+		goal.Period,
+		goal.CreatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert goal: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// DeleteGoal removes a goal
+func (db *Database) DeleteGoal(ctx context.Context, id int64) error {
+	result, err := db.Conn.ExecContext(ctx, `DELETE FROM goals WHERE id = ?;`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no goal with id %d", id)
+	}
+	return nil
+}
+
+// UpdateGoalProgress stores the latest evaluation of a goal
+func (db *Database) UpdateGoalProgress(ctx context.Context, progress models.GoalProgress) error {
+	var evaluatedAt any
+	if progress.EvaluatedAt != nil {
+		evaluatedAt = progress.EvaluatedAt.UTC().Format(time.RFC3339)
+	}
+	_, err := db.Conn.ExecContext(ctx, `UPDATE goals SET evaluated_at = ?, games = ?, value = ?, met = ? WHERE id = ?;`,
+		evaluatedAt,
+		progress.Games,
+		progress.Value,
+		progress.Met,
+		progress.Goal.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update progress of goal %d: %w", progress.Goal.ID, err)
+	}
+	return nil
+}
+
+// ListGoalProgress returns the goals with their latest evaluation, in the order they were added.
+// An empty summonerID returns the goals of every summoner.
+func (db *Database) ListGoalProgress(ctx context.Context, summonerID string) ([]models.GoalProgress, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT
+		id, summoner_id, metric, comparison, target, COALESCE(share, 0), COALESCE(position, ''), period, created_at,
+		evaluated_at, COALESCE(games, 0), COALESCE(value, 0), COALESCE(met, FALSE)
+	FROM goals
+	WHERE ? = '' OR summoner_id = ?
+	ORDER BY id;`, summonerID, summonerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goals: %w", err)
+	}
+	defer rows.Close()
+
+	goals := []models.GoalProgress{}
+	for rows.Next() {
+		var p models.GoalProgress
+		var createdAt string
+		var evaluatedAt sql.NullString
+		g := &p.Goal
+		if err := rows.Scan(&g.ID, &g.SummonerID, &g.Metric, &g.Comparison, &g.Target, &g.Share, &g.Position, &g.Period, &createdAt,
+			&evaluatedAt, &p.Games, &p.Value, &p.Met); err != nil {
+			return nil, err
+		}
+		if g.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at of goal %d: %w", g.ID, err)
+		}
+		if evaluatedAt.Valid {
+			t, err := time.Parse(time.RFC3339, evaluatedAt.String)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse evaluated_at of goal %d: %w", g.ID, err)
+			}
+			p.EvaluatedAt = &t
+		}
+		p.Description = g.String()
+		goals = append(goals, p)
+	}
+	return goals, rows.Err()
+}
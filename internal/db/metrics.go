@@ -0,0 +1,70 @@
+// internal/db/metrics.go
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// selectParticipantMetrics derives the participant_metrics columns from participants p, their games g
+// and their teams t. Damage share is taken from the sum over the team's participants, the teams table
+// does not record damage. A metric whose total is zero or not stored is NULL.
+const selectParticipantMetrics = `SELECT
+		p.id,
+		p.game_id,
+		CAST(p.kills + p.assists AS REAL) / MAX(p.deaths, 1),
+		CAST(p.kills + p.assists AS REAL) / NULLIF(t.kill, 0),
+		CAST(p.damage_dealt AS REAL) / NULLIF(team_damage.damage, 0),
+		CAST(p.gold_earned AS REAL) / NULLIF(t.gold_earned, 0),
+		(p.minion_kill + p.neutral_minion_kill) * 60.0 / NULLIF(g.game_length, 0),
+		p.vision_score * 60.0 / NULLIF(g.game_length, 0),
+		p.gold_earned * 60.0 / NULLIF(g.game_length, 0),
+		p.damage_dealt * 60.0 / NULLIF(g.game_length, 0),
+		CAST(p.damage_dealt AS REAL) / NULLIF(p.gold_earned, 0),
+		10 - p.op_score_rank
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	LEFT JOIN teams t ON t.game_id = p.game_id AND t.key = p.team_key
+	LEFT JOIN (
+		SELECT game_id, team_key, SUM(damage_dealt) AS damage
+		FROM participants
+		GROUP BY game_id, team_key
+	) team_damage ON team_damage.game_id = p.game_id AND team_damage.team_key = p.team_key`
+
+const insertParticipantMetrics = `INSERT OR REPLACE INTO participant_metrics (
+		participant_id, game_id, kda, kill_participation, damage_share, gold_share,
+		cs_per_minute, vision_per_minute, gold_per_minute, damage_per_minute, damage_per_gold, op_score
+	) `
+
+// UpdateParticipantMetrics derives the metrics of the participants of one game. Call it once the
+// game's teams and participants are stored.
+func (db *Database) UpdateParticipantMetrics(ctx context.Context, gameID string) error {
+	if _, err := db.Conn.ExecContext(ctx, insertParticipantMetrics+selectParticipantMetrics+`
+	WHERE p.game_id = ?;`, gameID); err != nil {
+		return fmt.Errorf("failed to update participant metrics of game %s: %w", gameID, err)
+	}
+	return nil
+}
+
+// RecomputeParticipantMetrics derives the metrics of every stored participant again, e.g. after a
+// formula changed, and returns the number of participants
+func (db *Database) RecomputeParticipantMetrics(ctx context.Context) (int64, error) {
+	tx, err := db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM participant_metrics;`); err != nil {
+		return 0, fmt.Errorf("failed to clear participant metrics: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, insertParticipantMetrics+selectParticipantMetrics+`;`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute participant metrics: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
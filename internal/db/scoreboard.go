@@ -0,0 +1,241 @@
+// internal/db/scoreboard.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"opggvisualizer/internal/models"
+	"time"
+)
+
+// ListGames returns the latest games of a summoner, newest first. The summoner is matched by id or by
+// name, games stored before summoner ids were recorded only have the name. Champion names are in locale
+// where a translation is stored, as in ListChampions.
+func (db *Database) ListGames(ctx context.Context, summonerID, summonerName, locale string, limit int) ([]models.GameSummary, error) {
+	return db.listGameSummaries(ctx, locale, `p.summoner_id = ? OR p.summoner_name = ?
+	ORDER BY g.created_at DESC
+	LIMIT ?`, summonerID, summonerName, limit)
+}
+
+// GetGameSummary returns one game from the point of view of a summoner, matched as in ListGames.
+// nil is returned if the summoner did not play the game.
+func (db *Database) GetGameSummary(ctx context.Context, gameID, summonerID, summonerName, locale string) (*models.GameSummary, error) {
+	games, err := db.listGameSummaries(ctx, locale, `g.game_id = ? AND (p.summoner_id = ? OR p.summoner_name = ?)
+	LIMIT 1`, gameID, summonerID, summonerName)
+	if err != nil || len(games) == 0 {
+		return nil, err
+	}
+	return &games[0], nil
+}
+
+// listGameSummaries lists the participants p of games g matched by condition, which may end with ORDER BY and LIMIT
+func (db *Database) listGameSummaries(ctx context.Context, locale, condition string, args ...any) ([]models.GameSummary, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT
+		g.game_id, g.created_at, g.game_length, COALESCE(g.patch, ''), g.is_remake,
+		COALESCE((SELECT GROUP_CONCAT(f.flag) FROM game_flags f WHERE f.game_id = g.game_id), ''),
+		p.summoner_name, p.champion_id, COALESCE(ct.name, c.name, ''), p.position, p.result, p.kills, p.deaths, p.assists
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	LEFT JOIN champions c ON c.champion_id = p.champion_id
+	LEFT JOIN champion_translations ct ON ct.champion_id = c.champion_id AND ct.locale = ?
+	WHERE `+condition+`;`, append([]any{locale}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list games: %w", err)
+	}
+	defer rows.Close()
+
+	games := []models.GameSummary{}
+	for rows.Next() {
+		var game models.GameSummary
+		var createdAt, flags string
+		if err := rows.Scan(&game.GameID, &createdAt, &game.GameLength, &game.Patch, &game.IsRemake, &flags,
+			&game.Summoner, &game.Champion.ID, &game.Champion.Name, &game.Position, &game.Result,
+			&game.Kills, &game.Deaths, &game.Assists); err != nil {
+			return nil, err
+		}
+		if game.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at of game %s: %w", game.GameID, err)
+		}
+		game.Flags = splitFlags(flags)
+		games = append(games, game)
+	}
+	return games, rows.Err()
+}
+
+// GetScoreboard returns both teams of a game with their objectives, bans and players, naming the champions
+// as in ListGames. nil is returned if the game is not stored.
+func (db *Database) GetScoreboard(ctx context.Context, gameID, locale string) (*models.Scoreboard, error) {
+	board := models.Scoreboard{GameID: gameID, Teams: []models.TeamScoreboard{}}
+	var createdAt, flags string
+	err := db.Conn.QueryRowContext(ctx, `SELECT created_at, game_length, version, COALESCE(patch, ''), is_remake,
+		COALESCE((SELECT GROUP_CONCAT(flag) FROM game_flags WHERE game_flags.game_id = games.game_id), '')
+	FROM games WHERE game_id = ?;`, gameID).Scan(&createdAt, &board.GameLength, &board.Version, &board.Patch, &board.IsRemake, &flags)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game %s: %w", gameID, err)
+	}
+	if board.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse created_at of game %s: %w", gameID, err)
+	}
+	board.Flags = splitFlags(flags)
+
+	teamIDs, err := db.loadScoreboardTeams(ctx, &board)
+	if err != nil {
+		return nil, err
+	}
+	for i := range board.Teams {
+		if board.Teams[i].Bans, err = db.listTeamBans(ctx, teamIDs[i], locale); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.loadScoreboardPlayers(ctx, &board, locale); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+// loadScoreboardTeams adds the teams of the game to board and returns their ids in the same order
+func (db *Database) loadScoreboardTeams(ctx context.Context, board *models.Scoreboard) ([]int, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT team_id, key, is_win, kill, death, assist, gold_earned,
+		dragon_kill, baron_kill, rift_herald_kill, horde_kill, tower_kill, inhibitor_kill,
+		champion_first, tower_first, dragon_first, rift_herald_first, horde_first, baron_first, inhibitor_first
+	FROM teams WHERE game_id = ? ORDER BY key;`, board.GameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	defer rows.Close()
+
+	var teamIDs []int
+	for rows.Next() {
+		var teamID int
+		var team models.TeamScoreboard
+		var firstBlood, firstTower, firstDragon, firstHerald, firstHorde, firstBaron, firstInhibitor bool
+		if err := rows.Scan(&teamID, &team.Key, &team.IsWin, &team.Kills, &team.Deaths, &team.Assists, &team.GoldEarned,
+			&team.Dragons, &team.Barons, &team.Heralds, &team.Hordes, &team.Towers, &team.Inhibitors,
+			&firstBlood, &firstTower, &firstDragon, &firstHerald, &firstHorde, &firstBaron, &firstInhibitor); err != nil {
+			return nil, err
+		}
+
+		team.Firsts = []string{}
+		for _, first := range []struct {
+			taken bool
+			name  string
+		}{
+			{firstBlood, "blood"}, {firstTower, "tower"}, {firstDragon, "dragon"}, {firstHerald, "herald"},
+			{firstHorde, "grubs"}, {firstBaron, "baron"}, {firstInhibitor, "inhibitor"},
+		} {
+			if first.taken {
+				team.Firsts = append(team.Firsts, first.name)
+			}
+		}
+		team.Players = []models.PlayerScoreboard{}
+
+		board.Teams = append(board.Teams, team)
+		teamIDs = append(teamIDs, teamID)
+	}
+	return teamIDs, rows.Err()
+}
+
+// listTeamBans returns the champions banned by a team in ban order, leaving out missed bans
+func (db *Database) listTeamBans(ctx context.Context, teamID int, locale string) ([]models.NamedID, error) {
+	bans, err := db.listNamedIDs(ctx, `SELECT b.champion_id, COALESCE(ct.name, c.name, '')
+	FROM team_banned_champions b
+	LEFT JOIN champions c ON c.champion_id = CAST(b.champion_id AS TEXT)
+	LEFT JOIN champion_translations ct ON ct.champion_id = c.champion_id AND ct.locale = ?
+	WHERE b.team_id = ? AND b.champion_id IS NOT NULL
+	ORDER BY b.pick_order;`, locale, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bans: %w", err)
+	}
+	return bans, nil
+}
+
+// loadScoreboardPlayers adds the participants of the game to their team in board
+func (db *Database) loadScoreboardPlayers(ctx context.Context, board *models.Scoreboard, locale string) error {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT p.id, p.team_key, p.summoner_name, p.champion_id, COALESCE(ct.name, c.name, ''), p.position,
+		p.kills, p.deaths, p.assists, p.minion_kill + p.neutral_minion_kill, p.gold_earned, p.damage_dealt, p.damage_taken,
+		p.vision_score, p.op_score_rank,
+		p.primary_rune_id, COALESCE(primary_rune.name, ''), p.secondary_rune_page_id, COALESCE(secondary_tree.name, '')
+	FROM participants p
+	LEFT JOIN champions c ON c.champion_id = p.champion_id
+	LEFT JOIN champion_translations ct ON ct.champion_id = c.champion_id AND ct.locale = ?
+	LEFT JOIN runes primary_rune ON primary_rune.rune_id = p.primary_rune_id
+	LEFT JOIN runes secondary_tree ON secondary_tree.rune_id = p.secondary_rune_page_id
+	WHERE p.game_id = ?
+	ORDER BY p.participant_id;`, locale, board.GameID)
+	if err != nil {
+		return fmt.Errorf("failed to list participants: %w", err)
+	}
+
+	// Collect the rows first, items and spells are queried per participant
+	type row struct {
+		id      int
+		teamKey string
+		player  models.PlayerScoreboard
+	}
+	var players []row
+	for rows.Next() {
+		var r row
+		var cs sql.NullInt64
+		p := &r.player
+		if err := rows.Scan(&r.id, &r.teamKey, &p.SummonerName, &p.Champion.ID, &p.Champion.Name, &p.Position,
+			&p.Kills, &p.Deaths, &p.Assists, &cs, &p.GoldEarned, &p.DamageDealt, &p.DamageTaken,
+			&p.VisionScore, &p.OPScoreRank,
+			&p.PrimaryRune.ID, &p.PrimaryRune.Name, &p.SecondaryTree.ID, &p.SecondaryTree.Name); err != nil {
+			rows.Close()
+			return err
+		}
+		if cs.Valid {
+			value := int(cs.Int64)
+			p.CS = &value
+		}
+		players = append(players, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range players {
+		if r.player.Items, err = db.listNamedIDs(ctx, `SELECT pi.item_id, COALESCE(i.name, '')
+		FROM participant_items pi LEFT JOIN items i ON i.item_id = pi.item_id
+		WHERE pi.participant_id = ? AND pi.item_id > 0 ORDER BY pi.id;`, r.id); err != nil {
+			return fmt.Errorf("failed to list participant items: %w", err)
+		}
+		if r.player.Spells, err = db.listNamedIDs(ctx, `SELECT ps.spell_id, COALESCE(s.name, '')
+		FROM participant_spells ps LEFT JOIN summoner_spells s ON s.spell_id = ps.spell_id
+		WHERE ps.participant_id = ? ORDER BY ps.id;`, r.id); err != nil {
+			return fmt.Errorf("failed to list participant spells: %w", err)
+		}
+
+		for i := range board.Teams {
+			if board.Teams[i].Key == r.teamKey {
+				board.Teams[i].Players = append(board.Teams[i].Players, r.player)
+			}
+		}
+	}
+	return nil
+}
+
+// listNamedIDs runs a query selecting an id and a name
+func (db *Database) listNamedIDs(ctx context.Context, query string, args ...any) ([]models.NamedID, error) {
+	rows, err := db.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	named := []models.NamedID{}
+	for rows.Next() {
+		var n models.NamedID
+		if err := rows.Scan(&n.ID, &n.Name); err != nil {
+			return nil, err
+		}
+		named = append(named, n)
+	}
+	return named, rows.Err()
+}
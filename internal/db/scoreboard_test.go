@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"opggvisualizer/internal/models"
+)
+
+func TestGameChampionNamesLocale(t *testing.T) {
+	ctx := context.Background()
+	database, err := Open(ctx, filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	ahri := models.Champion{ID: "Ahri", Key: "103", Name: "Ahri"}
+	if err := database.InsertChampion(ctx, ahri); err != nil {
+		t.Fatal(err)
+	}
+	ahri.Name = "阿狸"
+	if err := database.InsertChampionTranslation(ctx, ahri, "zh_CN"); err != nil {
+		t.Fatal(err)
+	}
+	storeTestGame(t, database, "game-1", 1800, false, 10, nil)
+
+	// de_DE has no translation stored and falls back to the en_US name
+	for locale, want := range map[string]string{"zh_CN": "阿狸", "de_DE": "Ahri"} {
+		games, err := database.ListGames(ctx, "", "player 1", locale, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(games) != 1 || games[0].Champion.Name != want {
+			t.Errorf("ListGames in %s = %v, want the champion named %s", locale, games, want)
+		}
+
+		board, err := database.GetScoreboard(ctx, "game-1", locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name := board.Teams[0].Players[0].Champion.Name; name != want {
+			t.Errorf("GetScoreboard in %s names the champion %s, want %s", locale, name, want)
+		}
+	}
+}
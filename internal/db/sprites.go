@@ -0,0 +1,60 @@
+// internal/db/sprites.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"opggvisualizer/internal/models"
+)
+
+// spriteTables maps an image group to the table and id column it is stored in
+var spriteTables = map[string]struct{ table, idColumn string }{
+	"champion": {"champions", "champion_id"},
+	"item":     {"items", "item_id"},
+	"spell":    {"summoner_spells", "spell_id"},
+}
+
+// GetSprite returns where the image of a champion, item or summoner spell is found in its sprite sheet.
+// group is "champion", "item" or "spell". nil is returned if the image is unknown.
+func (db *Database) GetSprite(ctx context.Context, group, id string) (*models.Sprite, error) {
+	source, ok := spriteTables[group]
+	if !ok {
+		return nil, nil
+	}
+
+	// Rows stored before sprite coordinates were recorded have no sprite until the next champion fetch
+	var sprite models.Sprite
+	query := fmt.Sprintf(`SELECT sprite_url, sprite_x, sprite_y, sprite_w, sprite_h FROM %s
+	WHERE %s = ? AND sprite_url IS NOT NULL;`, source.table, source.idColumn)
+	err := db.Conn.QueryRowContext(ctx, query, id).Scan(&sprite.URL, &sprite.X, &sprite.Y, &sprite.W, &sprite.H)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprite of %s %s: %w", group, id, err)
+	}
+	return &sprite, nil
+}
+
+// ListDraftPicks returns the champions picked in a game, ordered by team and participant
+func (db *Database) ListDraftPicks(ctx context.Context, gameID string) ([]models.DraftPick, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT team_key, champion_id FROM participants
+	WHERE game_id = ?
+	ORDER BY team_key, participant_id;`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list draft picks: %w", err)
+	}
+	defer rows.Close()
+
+	picks := []models.DraftPick{}
+	for rows.Next() {
+		var pick models.DraftPick
+		if err := rows.Scan(&pick.TeamKey, &pick.ChampionID); err != nil {
+			return nil, err
+		}
+		picks = append(picks, pick)
+	}
+	return picks, rows.Err()
+}
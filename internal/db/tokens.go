@@ -0,0 +1,79 @@
+// internal/db/tokens.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"opggvisualizer/internal/models"
+)
+
+// InsertAPIToken stores a new API token. Only the hash of the token is stored.
+func (db *Database) InsertAPIToken(ctx context.Context, name, sha256 string, createdAt time.Time) error {
+	_, err := db.Conn.ExecContext(ctx, `INSERT INTO api_tokens(name, sha256, created_at) VALUES (?, ?, ?);`,
+		name,
+		sha256,
+		createdAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert api token: %w", err)
+	}
+	return nil
+}
+
+// RevokeAPIToken marks the active token with the given name as revoked
+func (db *Database) RevokeAPIToken(ctx context.Context, name string, revokedAt time.Time) error {
+	result, err := db.Conn.ExecContext(ctx, `UPDATE api_tokens SET revoked_at = ? WHERE name = ? AND revoked_at IS NULL;`,
+		revokedAt.UTC().Format(time.RFC3339),
+		name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api token: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no active api token named %q", name)
+	}
+	return nil
+}
+
+// FindAPIToken returns the name of the active token with the given hash, or "" if there is none
+func (db *Database) FindAPIToken(ctx context.Context, sha256 string) (string, error) {
+	var name string
+	err := db.Conn.QueryRowContext(ctx, `SELECT name FROM api_tokens WHERE sha256 = ? AND revoked_at IS NULL;`, sha256).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up api token: %w", err)
+	}
+	return name, nil
+}
+
+// ListAPITokens returns all tokens, including revoked ones
+func (db *Database) ListAPITokens(ctx context.Context) ([]models.APIToken, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT name, created_at, revoked_at FROM api_tokens ORDER BY created_at;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []models.APIToken{}
+	for rows.Next() {
+		var token models.APIToken
+		var createdAt string
+		var revokedAt sql.NullString
+		if err := rows.Scan(&token.Name, &createdAt, &revokedAt); err != nil {
+			return nil, err
+		}
+		token.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		if revokedAt.Valid {
+			t, _ := time.Parse(time.RFC3339, revokedAt.String)
+			token.RevokedAt = &t
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
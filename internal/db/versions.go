@@ -0,0 +1,230 @@
+// internal/db/versions.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"opggvisualizer/internal/models"
+	"sort"
+	"time"
+)
+
+// InsertChampionVersion stores a champion as it was in the given ddragon version
+func (db *Database) InsertChampionVersion(ctx context.Context, champion models.Champion, version string) error {
+	tagsJSON, err := json.Marshal(champion.Tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	statsJSON, err := json.Marshal(champion.Stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+
+	insertChampionVersionSQL := `INSERT OR REPLACE INTO champion_versions(
+		champion_id, version, name, title, tags, type, format, blurb, partype,
+		attack, defense, magic, difficulty, stats, image_url
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	_, err = db.Conn.ExecContext(ctx, insertChampionVersionSQL,
+		champion.Key, // champion.Key matches participant's champion_id
+		version,
+		champion.Name,
+		champion.Title,
+		string(tagsJSON),
+		champion.Type,
+		champion.Format,
+		champion.Blurb,
+		champion.Partype,
+		champion.Info.Attack,
+		champion.Info.Defense,
+		champion.Info.Magic,
+		champion.Info.Difficulty,
+		string(statsJSON),
+		champion.Image.FullURL(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert champion version: %w", err)
+	}
+	return nil
+}
+
+// InsertItemVersion stores an item as it was in the given ddragon version
+func (db *Database) InsertItemVersion(ctx context.Context, itemID string, item models.Item, version string) error {
+	tagsJSON, err := json.Marshal(item.Tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	insertItemVersionSQL := `INSERT OR REPLACE INTO item_versions(
+		item_id, version, name, description, plaintext, gold_base, gold_total, tags, image_url
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	_, err = db.Conn.ExecContext(ctx, insertItemVersionSQL,
+		itemID,
+		version,
+		item.Name,
+		item.Description,
+		item.Plaintext,
+		int(item.Gold.Base),
+		int(item.Gold.Total),
+		string(tagsJSON),
+		item.Image.VersionedURL(version),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert item version: %w", err)
+	}
+	return nil
+}
+
+// RecordStaticVersion marks a ddragon version as stored
+func (db *Database) RecordStaticVersion(ctx context.Context, version string, fetchedAt time.Time) error {
+	_, err := db.Conn.ExecContext(ctx, `INSERT OR REPLACE INTO static_versions(version, patch, fetched_at) VALUES (?, ?, ?);`,
+		version,
+		models.Patch(version),
+		fetchedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record static version: %w", err)
+	}
+	return nil
+}
+
+// ListStaticVersions returns the stored ddragon versions, newest first
+func (db *Database) ListStaticVersions(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT version FROM static_versions;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list static versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []string{}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sortVersionsDesc(versions)
+	return versions, nil
+}
+
+// IsLatestStaticVersion reports whether no newer ddragon version than version is stored
+func (db *Database) IsLatestStaticVersion(ctx context.Context, version string) (bool, error) {
+	versions, err := db.ListStaticVersions(ctx)
+	if err != nil {
+		return false, err
+	}
+	return len(versions) == 0 || models.CompareVersions(version, versions[0]) >= 0, nil
+}
+
+// ResolveStaticVersion accepts a ddragon version or a patch and returns the newest stored ddragon version matching it
+func (db *Database) ResolveStaticVersion(ctx context.Context, versionOrPatch string) (string, error) {
+	var version string
+	err := db.Conn.QueryRowContext(ctx, `SELECT version FROM static_versions WHERE version = ?;`, versionOrPatch).Scan(&version)
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to resolve static version: %w", err)
+	}
+
+	versions, err := db.ListStaticVersions(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range versions {
+		if models.Patch(v) == versionOrPatch {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("no static data stored for %q, fetch it with: champions fetch --version <version>", versionOrPatch)
+}
+
+// ListPatchesWithoutStaticData returns the patches of stored games that have no static data version
+func (db *Database) ListPatchesWithoutStaticData(ctx context.Context) ([]string, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT DISTINCT games.patch
+		FROM games
+		LEFT JOIN static_versions ON static_versions.patch = games.patch
+		WHERE games.patch IS NOT NULL AND games.patch != '' AND static_versions.version IS NULL;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list patches without static data: %w", err)
+	}
+	defer rows.Close()
+
+	patches := []string{}
+	for rows.Next() {
+		var patch string
+		if err := rows.Scan(&patch); err != nil {
+			return nil, err
+		}
+		patches = append(patches, patch)
+	}
+	return patches, rows.Err()
+}
+
+// GetChampionVersions returns the champions of a ddragon version keyed by champion id
+func (db *Database) GetChampionVersions(ctx context.Context, version string) (map[string]models.ChampionVersion, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT champion_id, name, title, tags, partype, attack, defense, magic, difficulty, stats
+		FROM champion_versions WHERE version = ?;`, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get champion versions: %w", err)
+	}
+	defer rows.Close()
+
+	champions := make(map[string]models.ChampionVersion)
+	for rows.Next() {
+		champion := models.ChampionVersion{Version: version}
+		var tags, stats string
+		if err := rows.Scan(&champion.ChampionID, &champion.Name, &champion.Title, &tags, &champion.Partype,
+			&champion.Info.Attack, &champion.Info.Defense, &champion.Info.Magic, &champion.Info.Difficulty, &stats); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(tags), &champion.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags of champion %s: %w", champion.ChampionID, err)
+		}
+		if err := json.Unmarshal([]byte(stats), &champion.Stats); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stats of champion %s: %w", champion.ChampionID, err)
+		}
+		champions[champion.ChampionID] = champion
+	}
+	return champions, rows.Err()
+}
+
+// GetItemVersions returns the items of a ddragon version keyed by item id
+func (db *Database) GetItemVersions(ctx context.Context, version string) (map[int]models.ItemVersion, error) {
+	rows, err := db.Conn.QueryContext(ctx, `SELECT item_id, name, plaintext, gold_base, gold_total, tags
+		FROM item_versions WHERE version = ?;`, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item versions: %w", err)
+	}
+	defer rows.Close()
+
+	items := make(map[int]models.ItemVersion)
+	for rows.Next() {
+		item := models.ItemVersion{Version: version}
+		var tags string
+		if err := rows.Scan(&item.ItemID, &item.Name, &item.Plaintext, &item.GoldBase, &item.GoldTotal, &tags); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(tags), &item.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags of item %d: %w", item.ItemID, err)
+		}
+		items[item.ItemID] = item
+	}
+	return items, rows.Err()
+}
+
+func sortVersionsDesc(versions []string) {
+	sort.Slice(versions, func(i, j int) bool {
+		return models.CompareVersions(versions[i], versions[j]) > 0
+	})
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"opggvisualizer/internal/models"
+)
+
+func TestGameStaticVersionsNewestByNumber(t *testing.T) {
+	ctx := context.Background()
+	database, err := Open(ctx, filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	for _, version := range []string{"14.20.1", "14.20.9", "14.20.10", "14.21.1"} {
+		if err := database.RecordStaticVersion(ctx, version, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	game := models.Game{ID: "game-1", CreatedAt: time.Date(2024, 11, 4, 18, 0, 0, 0, time.UTC), Version: "14.20.612.4242"}
+	if err := database.InsertGame(ctx, game); err != nil {
+		t.Fatal(err)
+	}
+
+	var version string
+	if err := database.Conn.QueryRowContext(ctx, `SELECT static_version FROM game_static_versions WHERE game_id = 'game-1';`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != "14.20.10" {
+		t.Errorf("static version of a 14.20 game = %s, want 14.20.10", version)
+	}
+}
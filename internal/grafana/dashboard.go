@@ -0,0 +1,351 @@
+// internal/grafana/dashboard.go
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"opggvisualizer/internal/stats"
+)
+
+// DashboardPath is the provisioned dashboard, relative to the root of the repository
+const DashboardPath = "grafana/provisioning/dashboards/mydashboards/mydashboard.json"
+
+// gridWidth is the number of columns of the Grafana grid
+const gridWidth = 24
+
+// pluginVersion is the Grafana version the panels were last saved with
+const pluginVersion = "11.4.0"
+
+// sqlite is the datasource provisioned by grafana/provisioning/datasources
+var sqlite = Datasource{Type: "frser-sqlite-datasource", UID: "P2D2EEF3E092AF52B"}
+
+// defaultThresholds are the thresholds Grafana gives a new panel
+var defaultThresholds = map[string]any{
+	"mode":  "absolute",
+	"steps": []any{map[string]any{"color": "green", "value": nil}, map[string]any{"color": "red", "value": 80}},
+}
+
+// Dashboard is the JSON model of a Grafana dashboard. Fields are declared in the order Grafana exports them.
+type Dashboard struct {
+	Annotations          Annotations `json:"annotations"`
+	Editable             bool        `json:"editable"`
+	FiscalYearStartMonth int         `json:"fiscalYearStartMonth"`
+	GraphTooltip         int         `json:"graphTooltip"`
+	Links                []any       `json:"links"`
+	Panels               []Panel     `json:"panels"`
+	Preload              bool        `json:"preload"`
+	SchemaVersion        int         `json:"schemaVersion"`
+	Tags                 []string    `json:"tags"`
+	Templating           Templating  `json:"templating"`
+	Time                 TimeRange   `json:"time"`
+	Timepicker           struct{}    `json:"timepicker"`
+	Timezone             string      `json:"timezone"`
+	Title                string      `json:"title"`
+	UID                  string      `json:"uid"`
+	Version              int         `json:"version"`
+	WeekStart            string      `json:"weekStart"`
+}
+
+type Annotations struct {
+	List []Annotation `json:"list"`
+}
+
+type Annotation struct {
+	BuiltIn    int        `json:"builtIn"`
+	Datasource Datasource `json:"datasource"`
+	Enable     bool       `json:"enable"`
+	Hide       bool       `json:"hide"`
+	IconColor  string     `json:"iconColor"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+}
+
+type Datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Panel is a row, a time series or a table. Rows hold their panels while they are collapsed.
+type Panel struct {
+	Collapsed       *bool            `json:"collapsed,omitempty"`
+	Datasource      *Datasource      `json:"datasource,omitempty"`
+	Description     string           `json:"description,omitempty"`
+	FieldConfig     map[string]any   `json:"fieldConfig,omitempty"`
+	GridPos         GridPos          `json:"gridPos"`
+	ID              int              `json:"id"`
+	Options         map[string]any   `json:"options,omitempty"`
+	Panels          []Panel          `json:"panels,omitempty"`
+	PluginVersion   string           `json:"pluginVersion,omitempty"`
+	Targets         []Target         `json:"targets,omitempty"`
+	Title           string           `json:"title"`
+	Transformations []Transformation `json:"transformations,omitempty"`
+	Type            string           `json:"type"`
+}
+
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Target is a query of the SQLite datasource
+type Target struct {
+	Datasource   Datasource `json:"datasource"`
+	QueryText    string     `json:"queryText"`
+	QueryType    string     `json:"queryType"`
+	RawQueryText string     `json:"rawQueryText"`
+	RefID        string     `json:"refId"`
+	TimeColumns  []string   `json:"timeColumns"`
+}
+
+type Transformation struct {
+	ID      string         `json:"id"`
+	Options map[string]any `json:"options"`
+}
+
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// Variable is a dashboard variable, either a custom list of options or the values returned by a query
+type Variable struct {
+	Definition  string           `json:"definition,omitempty"`
+	Description string           `json:"description"`
+	Label       string           `json:"label"`
+	Multi       bool             `json:"multi,omitempty"`
+	Name        string           `json:"name"`
+	Options     []VariableOption `json:"options"`
+	Query       string           `json:"query"`
+	Refresh     int              `json:"refresh,omitempty"`
+	Type        string           `json:"type"`
+}
+
+type VariableOption struct {
+	Selected bool   `json:"selected"`
+	Text     string `json:"text"`
+	Value    string `json:"value"`
+}
+
+// notExcluded stands for the condition leaving out the games excluded by the stats.exclude setting in
+// the queries of the specs. Build replaces it, see excludeGames.
+const notExcluded = "{{not excluded}}"
+
+// notExcludedLine matches a condition on notExcluded with the line it starts
+var notExcludedLine = regexp.MustCompile(`\n\s*AND ` + regexp.QuoteMeta(notExcluded))
+
+// row is a titled group of panels
+type row struct {
+	title     string
+	collapsed bool
+	panels    []Panel // Sized but not placed
+}
+
+// Build assembles the dashboard from the metrics, breakdowns and tables defined in this package. The
+// queries leave out the games with any of the flags in exclude, like the stats do.
+func Build(exclude []string) Dashboard {
+	rows := []row{cumulativeRow()}
+	for _, b := range breakdowns {
+		rows = append(rows, breakdownRow(b))
+	}
+	for _, t := range tableRows {
+		rows = append(rows, tableRow(t))
+	}
+
+	variables := []Variable{}
+	for _, b := range breakdowns {
+		variables = append(variables, b.variable())
+	}
+	variables = append(variables, summonerVariable)
+
+	return Dashboard{
+		Annotations: Annotations{List: []Annotation{{
+			BuiltIn:    1,
+			Datasource: Datasource{Type: "grafana", UID: "-- Grafana --"},
+			Enable:     true,
+			Hide:       true,
+			IconColor:  "rgba(0, 211, 255, 1)",
+			Name:       "Annotations & Alerts",
+			Type:       "dashboard",
+		}}},
+		Editable:      true,
+		Links:         []any{},
+		Panels:        excludeGames(layout(rows), stats.ExcludeCondition("games.game_id", exclude)),
+		SchemaVersion: 40,
+		Tags:          []string{},
+		Templating:    Templating{List: variables},
+		Time:          TimeRange{From: "now-3d", To: "now"},
+		Timezone:      "browser",
+		Title:         "League Stats",
+		UID:           "be8tq86iyycxsd",
+		Version:       1,
+	}
+}
+
+// layout numbers the panels and places them on the grid from top to bottom. The panels of a row fill
+// it from left to right. A collapsed row keeps its panels, placed as they appear once it is expanded.
+func layout(rows []row) []Panel {
+	panels := []Panel{}
+	id, y := 1, 0
+	for _, r := range rows {
+		collapsed := r.collapsed
+		rowPanel := Panel{Collapsed: &collapsed, GridPos: GridPos{H: 1, W: gridWidth, Y: y}, ID: id, Title: r.title, Type: "row"}
+		id++
+		y++
+
+		children := []Panel{}
+		x, height := 0, 0
+		for _, p := range r.panels {
+			if x+p.GridPos.W > gridWidth {
+				x, y, height = 0, y+height, 0
+			}
+			p.ID = id
+			p.GridPos.X, p.GridPos.Y = x, y
+			id++
+			x += p.GridPos.W
+			height = max(height, p.GridPos.H)
+			children = append(children, p)
+		}
+		y += height
+
+		if r.collapsed {
+			rowPanel.Panels = children
+			panels = append(panels, rowPanel)
+			// The rows below a collapsed row start right under it
+			y = rowPanel.GridPos.Y + 1
+		} else {
+			panels = append(panels, rowPanel)
+			panels = append(panels, children...)
+		}
+	}
+	return panels
+}
+
+// excludeGames writes condition in place of notExcluded in the queries of the panels, the panels of
+// collapsed rows included. The conditions on notExcluded are dropped when condition is empty.
+func excludeGames(panels []Panel, condition string) []Panel {
+	for i := range panels {
+		for j := range panels[i].Targets {
+			t := &panels[i].Targets[j]
+			if condition == "" {
+				t.QueryText = notExcludedLine.ReplaceAllString(t.QueryText, "")
+			} else {
+				t.QueryText = strings.ReplaceAll(t.QueryText, notExcluded, condition)
+			}
+			t.RawQueryText = t.QueryText
+		}
+		panels[i].Panels = excludeGames(panels[i].Panels, condition)
+	}
+	return panels
+}
+
+// target queries the SQLite datasource. The query and the raw query both hold the query with its
+// variables, as the datasource saves a query that was not edited since it last ran.
+func target(refID, query, queryType string, timeColumns ...string) Target {
+	return Target{Datasource: sqlite, QueryText: query, QueryType: queryType, RawQueryText: query, RefID: refID, TimeColumns: timeColumns}
+}
+
+// Generate renders the dashboard as it is provisioned: indented JSON with the keys in a stable order
+func Generate(exclude []string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // Keep the SQL readable, e.g. "<>"
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Build(exclude)); err != nil {
+		return nil, fmt.Errorf("failed to encode dashboard: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Drift compares a provisioned dashboard with the generated one and describes every difference: the
+// panels added, removed or changed, keyed by row and title, and the dashboard settings. It returns no
+// differences when provisioned is exactly what Generate writes.
+func Drift(provisioned []byte, exclude []string) ([]string, error) {
+	generated, err := Generate(exclude)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(provisioned, generated) {
+		return nil, nil
+	}
+
+	var current Dashboard
+	if err := json.Unmarshal(provisioned, &current); err != nil {
+		return nil, fmt.Errorf("failed to parse provisioned dashboard: %w", err)
+	}
+	want := Build(exclude)
+
+	diffs := []string{}
+	currentKeys, currentPanels := indexPanels(current.Panels)
+	wantKeys, wantPanels := indexPanels(want.Panels)
+	for _, key := range wantKeys {
+		p, ok := currentPanels[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, "missing panel "+key)
+		case !reflect.DeepEqual(normalizePanel(p), normalizePanel(wantPanels[key])):
+			diffs = append(diffs, "changed panel "+key)
+		}
+	}
+	for _, key := range currentKeys {
+		if _, ok := wantPanels[key]; !ok {
+			diffs = append(diffs, "unexpected panel "+key)
+		}
+	}
+
+	current.Panels, want.Panels = nil, nil
+	if !reflect.DeepEqual(roundTrip(current), roundTrip(want)) {
+		diffs = append(diffs, "changed dashboard settings or variables")
+	}
+	if len(diffs) == 0 {
+		diffs = append(diffs, "changed panel ids, formatting or fields the generator does not write")
+	}
+	return diffs, nil
+}
+
+// indexPanels keys the panels of a dashboard, rows included, by "Row / Title" and lists the keys in
+// dashboard order
+func indexPanels(panels []Panel) ([]string, map[string]Panel) {
+	keys, byKey := []string{}, map[string]Panel{}
+	rowTitle := ""
+	for _, p := range panels {
+		key := p.Title
+		if p.Type == "row" {
+			rowTitle = p.Title
+		} else {
+			key = rowTitle + " / " + p.Title
+		}
+		keys = append(keys, key)
+		byKey[key] = p
+		for _, child := range p.Panels {
+			key := rowTitle + " / " + child.Title
+			keys = append(keys, key)
+			byKey[key] = child
+		}
+	}
+	return keys, byKey
+}
+
+// normalizePanel leaves out the id and the nested panels of a row, which are compared on their own, and
+// decodes the panel the way it was parsed so that numbers and empty values compare equal
+func normalizePanel(p Panel) any {
+	p.ID, p.Panels = 0, nil
+	return roundTrip(p)
+}
+
+func roundTrip(v any) any {
+	data, _ := json.Marshal(v)
+	var out any
+	_ = json.Unmarshal(data, &out)
+	return out
+}
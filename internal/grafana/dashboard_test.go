@@ -0,0 +1,34 @@
+package grafana
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opggvisualizer/internal/config"
+)
+
+// TestProvisionedDashboard fails when the provisioned dashboard was edited by hand or the specs changed
+// without running "opggvisualizer grafana generate"
+func TestProvisionedDashboard(t *testing.T) {
+	// The provisioned dashboard is generated with the default stats.exclude setting
+	t.Setenv("CONFIG_PATH", "")
+	t.Setenv("STATS_EXCLUDE", "")
+	cfg, err := config.Load("", config.Overrides{SummonerID: "dashboard"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provisioned, err := os.ReadFile(filepath.Join("..", "..", DashboardPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffs, err := Drift(provisioned, cfg.Stats.Exclude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) > 0 {
+		t.Errorf("%s is out of date, run: opggvisualizer grafana generate\n  %s", DashboardPath, strings.Join(diffs, "\n  "))
+	}
+}
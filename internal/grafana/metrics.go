@@ -0,0 +1,287 @@
+// internal/grafana/metrics.go
+package grafana
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metric is a value of one participant in one game. Every metric gets a panel in the Cumulative row
+// and in the row of every breakdown.
+type Metric struct {
+	Title  string  // Panel title, e.g. "OP Score"
+	Column string  // SQL expression of the value
+	Join   string  // Table joined to participants for Column, empty when participants holds the value
+	Max    float64 // Upper bound of the y axis starting at 0, 0 lets Grafana scale it
+	Trend  int     // Games before and after each game averaged into the trendline of the Cumulative row
+}
+
+var metrics = []Metric{
+	{
+		Title:  "OP Score",
+		Column: "participant_metrics.op_score",
+		Join:   "participant_metrics ON participant_metrics.participant_id = participants.id",
+		Max:    10,
+		Trend:  5,
+	},
+	{
+		Title:  "Vision Score",
+		Column: "participants.vision_score",
+		Trend:  5,
+	},
+	{
+		Title:  "Lane Score",
+		Column: "participants.lane_score",
+		Trend:  2,
+	},
+}
+
+// Breakdown splits the games of the selected summoners into one series per value of a column. Every
+// breakdown gets a collapsed row with a panel per metric and a variable choosing the values shown.
+type Breakdown struct {
+	Title       string           // Row title, e.g. "By Side"
+	Column      string           // SQL expression the games are split by
+	Label       string           // Name of the column in the query and label of the variable
+	Variable    string           // Name of the variable choosing the values shown
+	Description string           // Description of the variable
+	Options     []VariableOption // Values of a custom variable, empty when ValuesQuery returns them
+	ValuesQuery string
+	Colors      []SeriesColor // Fixed colors of some series
+}
+
+// SeriesColor colors the series whose name contains Value
+type SeriesColor struct {
+	Value string
+	Color string
+}
+
+var breakdowns = []Breakdown{
+	{
+		Title:       "By Side",
+		Column:      "participants.team_key",
+		Label:       "Side",
+		Variable:    "SIDE",
+		Description: "Which side of the map the game was on",
+		Options:     []VariableOption{{Selected: true, Text: "Red", Value: "RED"}, {Selected: true, Text: "Blue", Value: "BLUE"}},
+		Colors:      []SeriesColor{{Value: "RED", Color: "dark-red"}, {Value: "BLUE", Color: "dark-blue"}},
+	},
+	{
+		Title:       "By Lane",
+		Column:      "participants.position",
+		Label:       "Lane",
+		Variable:    "LANE",
+		Description: "The lane the game was played in",
+		Options: []VariableOption{
+			{Selected: true, Text: "Top", Value: "TOP"},
+			{Selected: true, Text: "Jungle", Value: "JUNGLE"},
+			{Selected: true, Text: "Middle", Value: "MIDDLE"},
+			{Selected: true, Text: "Bottom", Value: "BOTTOM"},
+		},
+	},
+	{
+		Title:       "By Role",
+		Column:      "participants.role",
+		Label:       "Role",
+		Variable:    "ROLE",
+		Description: "The role played",
+		ValuesQuery: `SELECT
+    participants.role
+FROM
+    participants
+JOIN
+    games ON participants.game_id = games.game_id
+WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+GROUP BY participants.role;`,
+	},
+	{
+		Title:       "By Champion",
+		Column:      "champions.name",
+		Label:       "Champion",
+		Variable:    "CHAMPION",
+		Description: "The champion played",
+		ValuesQuery: `SELECT
+    champions.name
+FROM
+    participants
+JOIN
+    games ON participants.game_id = games.game_id
+JOIN
+    champions ON participants.champion_id = champions.champion_id
+WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+GROUP BY
+    champions.champion_id;`,
+	},
+}
+
+// summonerVariable chooses the summoners every query is restricted to
+var summonerVariable = Variable{
+	Definition:  summonerQuery,
+	Description: "The summoner name of the primary summoner",
+	Label:       "Summoner",
+	Name:        "SUMMONER_NAME",
+	Options:     []VariableOption{},
+	Query:       summonerQuery,
+	Refresh:     1,
+	Type:        "query",
+}
+
+const summonerQuery = `SELECT participants.summoner_name
+FROM participants
+GROUP BY participants.summoner_name
+ORDER BY count(*) desc
+LIMIT 1`
+
+// variable is the dashboard variable choosing the values of the breakdown
+func (b Breakdown) variable() Variable {
+	v := Variable{Description: b.Description, Label: b.Label, Multi: true, Name: b.Variable, Options: []VariableOption{}}
+	if b.ValuesQuery != "" {
+		v.Definition, v.Query, v.Refresh, v.Type = b.ValuesQuery, b.ValuesQuery, 1, "query"
+		return v
+	}
+	choices := []string{}
+	for _, o := range b.Options {
+		choices = append(choices, o.Text+" : "+o.Value)
+	}
+	v.Options, v.Query, v.Type = b.Options, strings.Join(choices, ", "), "custom"
+	return v
+}
+
+// from joins the tables the query of a metric reads, each line indented by indent
+func from(m Metric, indent string) string {
+	joins := []string{"games ON participants.game_id = games.game_id"}
+	if m.Join != "" {
+		joins = append(joins, m.Join)
+	}
+	joins = append(joins, "champions ON participants.champion_id = champions.champion_id")
+
+	var b strings.Builder
+	b.WriteString(indent + "FROM\n" + indent + "    participants\n")
+	for _, join := range joins {
+		b.WriteString(indent + "JOIN\n" + indent + "    " + join + "\n")
+	}
+	b.WriteString(indent + "WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})\n")
+	b.WriteString(indent + "    AND " + notExcluded)
+	return b.String()
+}
+
+// cumulativeQuery selects the value of every game with a moving average of the Trend games around it
+func cumulativeQuery(m Metric) string {
+	return fmt.Sprintf(`WITH RankedData AS (
+    SELECT
+        games.created_at AS 'time',
+        %[1]s AS '%[2]s',
+        ROW_NUMBER() OVER (ORDER BY games.created_at ASC) AS row_num
+%[3]s
+),
+MovingAverage AS (
+    SELECT
+        time,
+        [%[2]s],
+        (SELECT AVG([%[2]s])
+         FROM RankedData r2
+         WHERE r2.row_num BETWEEN r1.row_num - %[4]d AND r1.row_num + %[4]d) AS Trendline
+    FROM RankedData r1
+)
+SELECT
+    time,
+    [%[2]s],
+    Trendline
+FROM MovingAverage
+ORDER BY time ASC;`, m.Column, m.Title, from(m, "    "), m.Trend)
+}
+
+// breakdownQuery selects the value of every game with the value of the breakdown column. The SQLite
+// datasource turns it into a series per breakdown value, named "Score <value>".
+func breakdownQuery(m Metric, b Breakdown) string {
+	return fmt.Sprintf(`SELECT
+    games.created_at AS 'time',
+    %s AS '%s',
+    %s AS 'Score'
+%s
+    AND %s IN (${%s:singlequote})
+ORDER BY
+    games.created_at ASC;`, b.Column, b.Label, m.Column, from(m, ""), b.Column, b.Variable)
+}
+
+func cumulativeRow() row {
+	r := row{title: "Cumulative"}
+	for _, m := range metrics {
+		// Colored by name, the value and its trendline keep their colors in every panel
+		fieldConfig := timeseriesFieldConfig(m, "palette-classic-by-name", nil)
+		r.panels = append(r.panels, timeseriesPanel(m.Title, cumulativeQuery(m), fieldConfig, nil))
+	}
+	return r
+}
+
+func breakdownRow(b Breakdown) row {
+	overrides := []any{}
+	for _, c := range b.Colors {
+		overrides = append(overrides, map[string]any{
+			"matcher":    map[string]any{"id": "byRegexp", "options": "/.*" + c.Value + "/"},
+			"properties": []any{map[string]any{"id": "color", "value": map[string]any{"fixedColor": c.Color, "mode": "fixed"}}},
+		})
+	}
+	// Drop the "Score " prefix the datasource gives the series
+	rename := []Transformation{{ID: "renameByRegex", Options: map[string]any{"regex": "Score (.*)", "renamePattern": "$1"}}}
+
+	r := row{title: b.Title, collapsed: true}
+	for _, m := range metrics {
+		r.panels = append(r.panels, timeseriesPanel(m.Title, breakdownQuery(m, b), timeseriesFieldConfig(m, "palette-classic", overrides), rename))
+	}
+	return r
+}
+
+func timeseriesPanel(title, query string, fieldConfig map[string]any, transformations []Transformation) Panel {
+	return Panel{
+		Datasource:  &sqlite,
+		FieldConfig: fieldConfig,
+		GridPos:     GridPos{H: 8, W: gridWidth},
+		Options: map[string]any{
+			"legend":  map[string]any{"calcs": []any{}, "displayMode": "list", "placement": "bottom", "showLegend": true},
+			"tooltip": map[string]any{"mode": "single", "sort": "none"},
+		},
+		PluginVersion:   pluginVersion,
+		Targets:         []Target{target(title, query, "time series", "time")},
+		Title:           title,
+		Transformations: transformations,
+		Type:            "timeseries",
+	}
+}
+
+func timeseriesFieldConfig(m Metric, colorMode string, overrides []any) map[string]any {
+	defaults := map[string]any{
+		"color": map[string]any{"mode": colorMode},
+		"custom": map[string]any{
+			"axisBorderShow":    false,
+			"axisCenteredZero":  false,
+			"axisColorMode":     "text",
+			"axisLabel":         "",
+			"axisPlacement":     "auto",
+			"barAlignment":      0,
+			"barWidthFactor":    0.6,
+			"drawStyle":         "line",
+			"fillOpacity":       0,
+			"gradientMode":      "none",
+			"hideFrom":          map[string]any{"legend": false, "tooltip": false, "viz": false},
+			"insertNulls":       false,
+			"lineInterpolation": "linear",
+			"lineStyle":         map[string]any{"fill": "solid"},
+			"lineWidth":         3,
+			"pointSize":         5,
+			"scaleDistribution": map[string]any{"type": "linear"},
+			"showPoints":        "auto",
+			"spanNulls":         true,
+			"stacking":          map[string]any{"group": "A", "mode": "none"},
+			"thresholdsStyle":   map[string]any{"mode": "off"},
+		},
+		"mappings":   []any{},
+		"thresholds": defaultThresholds,
+	}
+	if m.Max != 0 {
+		defaults["min"], defaults["max"] = 0, m.Max
+	}
+	if overrides == nil {
+		overrides = []any{}
+	}
+	return map[string]any{"defaults": defaults, "overrides": overrides}
+}
@@ -0,0 +1,320 @@
+// internal/grafana/tables.go
+package grafana
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Table is a table panel showing the rows returned by a query
+type Table struct {
+	Title       string
+	Description string
+	Width       int      // Columns out of 24
+	Height      int      // Grid rows
+	Percent     []string // Columns holding a percentage
+	Query       string
+}
+
+// TableRow is a collapsed row of tables, filled from left to right
+type TableRow struct {
+	Title  string
+	Tables []Table
+}
+
+var tableRows = []TableRow{
+	{
+		Title: "Synergy",
+		Tables: []Table{
+			{
+				Title:       "Teammates",
+				Description: "Record of the selected summoners in the games played together with each teammate",
+				Width:       12,
+				Height:      10,
+				Percent:     []string{"Win Rate"},
+				Query: `SELECT
+    teammates.summoner_name AS 'Teammate',
+    COUNT(*) AS 'Games',
+    SUM(participants.result = 'WIN') AS 'Wins',
+    ROUND(100.0 * SUM(participants.result = 'WIN') / COUNT(*), 1) AS 'Win Rate',
+    ROUND(AVG(participant_metrics.kda), 2) AS 'KDA',
+    ROUND(AVG(participants.op_score_rank), 1) AS 'OP Score Rank'
+FROM
+    participants
+JOIN
+    games ON participants.game_id = games.game_id
+LEFT JOIN
+    participant_metrics ON participant_metrics.participant_id = participants.id
+JOIN
+    participants teammates ON teammates.game_id = participants.game_id
+        AND teammates.team_key = participants.team_key
+        AND teammates.id <> participants.id
+WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+    AND teammates.summoner_name NOT IN (${SUMMONER_NAME:singlequote})
+    AND {{not excluded}}
+GROUP BY teammates.summoner_name
+HAVING COUNT(*) >= 2
+ORDER BY COUNT(*) DESC;`,
+			},
+			{
+				Title:       "Champions With and Against",
+				Description: "Record of the selected summoners when a champion is on their team or on the enemy team",
+				Width:       12,
+				Height:      10,
+				Percent:     []string{"Win Rate"},
+				Query: `SELECT
+    champions.name AS 'Champion',
+    CASE WHEN others.team_key = participants.team_key THEN 'With' ELSE 'Against' END AS 'Side',
+    COUNT(*) AS 'Games',
+    SUM(participants.result = 'WIN') AS 'Wins',
+    ROUND(100.0 * SUM(participants.result = 'WIN') / COUNT(*), 1) AS 'Win Rate'
+FROM
+    participants
+JOIN
+    games ON participants.game_id = games.game_id
+JOIN
+    participants others ON others.game_id = participants.game_id
+        AND others.id <> participants.id
+JOIN
+    champions ON others.champion_id = champions.champion_id
+WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+    AND {{not excluded}}
+GROUP BY others.champion_id, Side
+HAVING COUNT(*) >= 2
+ORDER BY Side DESC, COUNT(*) DESC;`,
+			},
+		},
+	},
+	{
+		Title: "Bans",
+		Tables: []Table{
+			{
+				Title:       "Banned Against Us",
+				Description: "Champions the enemy team banned in the games of the selected summoners",
+				Width:       12,
+				Height:      10,
+				Percent:     []string{"Win Rate"},
+				Query: `SELECT
+    game_bans.champion_name AS 'Champion',
+    COUNT(*) AS 'Bans',
+    ROUND(AVG(game_bans.pick_order), 1) AS 'Ban Order',
+    SUM(participants.result = 'WIN') AS 'Wins',
+    ROUND(100.0 * SUM(participants.result = 'WIN') / COUNT(*), 1) AS 'Win Rate'
+FROM
+    participants
+JOIN
+    games ON participants.game_id = games.game_id
+JOIN
+    game_bans ON game_bans.game_id = participants.game_id
+        AND game_bans.team_key <> participants.team_key
+WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+    AND {{not excluded}}
+GROUP BY game_bans.champion_id
+ORDER BY COUNT(*) DESC
+LIMIT 20;`,
+			},
+			{
+				Title:       "Our Bans",
+				Description: "Champions the team of the selected summoners banned",
+				Width:       12,
+				Height:      10,
+				Percent:     []string{"Win Rate"},
+				Query: `SELECT
+    game_bans.champion_name AS 'Champion',
+    COUNT(*) AS 'Bans',
+    ROUND(AVG(game_bans.pick_order), 1) AS 'Ban Order',
+    SUM(participants.result = 'WIN') AS 'Wins',
+    ROUND(100.0 * SUM(participants.result = 'WIN') / COUNT(*), 1) AS 'Win Rate'
+FROM
+    participants
+JOIN
+    games ON participants.game_id = games.game_id
+JOIN
+    game_bans ON game_bans.game_id = participants.game_id
+        AND game_bans.team_key = participants.team_key
+WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+    AND {{not excluded}}
+GROUP BY game_bans.champion_id
+ORDER BY COUNT(*) DESC
+LIMIT 20;`,
+			},
+			{
+				Title:       "Banned or Open",
+				Description: "Record of the selected summoners when a champion was banned by either team compared with the games it was open",
+				Width:       24,
+				Height:      10,
+				Percent:     []string{"Banned Win Rate", "Open Win Rate"},
+				Query: `SELECT
+    banned.champion_name AS 'Champion',
+    COUNT(*) AS 'Banned Games',
+    ROUND(100.0 * SUM(participants.result = 'WIN') / COUNT(*), 1) AS 'Banned Win Rate',
+    overall.games - COUNT(*) AS 'Open Games',
+    ROUND(100.0 * (overall.wins - SUM(participants.result = 'WIN')) / MAX(overall.games - COUNT(*), 1), 1) AS 'Open Win Rate'
+FROM
+    participants
+JOIN
+    games ON participants.game_id = games.game_id
+JOIN
+    (SELECT DISTINCT game_id, champion_id, champion_name FROM game_bans) banned ON banned.game_id = participants.game_id
+JOIN
+    (SELECT COUNT(*) AS games, SUM(participants.result = 'WIN') AS wins
+    FROM participants
+    JOIN games ON participants.game_id = games.game_id
+    WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+        AND {{not excluded}}) overall
+WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+    AND {{not excluded}}
+GROUP BY banned.champion_id
+HAVING COUNT(*) >= 2
+ORDER BY COUNT(*) DESC;`,
+			},
+		},
+	},
+	{
+		Title: "Objectives",
+		Tables: []Table{
+			{
+				Title:       "First Objectives",
+				Description: "Win rate of the selected summoners when their team took an objective first and when the enemy team did",
+				Width:       12,
+				Height:      9,
+				Percent:     []string{"Taken Win Rate", "Conceded Win Rate"},
+				Query:       firstObjectivesQuery(),
+			},
+			{
+				Title:       "Gold Difference by Side",
+				Description: "Team gold minus enemy team gold per game on each side of the map",
+				Width:       12,
+				Height:      9,
+				Query: `SELECT
+    teams.key AS 'Side',
+    COUNT(*) AS 'Games',
+    ROUND(100.0 * SUM(participants.result = 'WIN') / COUNT(*), 1) AS 'Win Rate',
+    ROUND(AVG(teams.gold_earned - enemy.gold_earned)) AS 'Gold Difference',
+    ROUND(AVG(CASE WHEN participants.result = 'WIN' THEN teams.gold_earned - enemy.gold_earned END)) AS 'In Wins',
+    ROUND(AVG(CASE WHEN participants.result <> 'WIN' THEN teams.gold_earned - enemy.gold_earned END)) AS 'In Losses'
+FROM
+    participants
+JOIN
+    games ON participants.game_id = games.game_id
+JOIN
+    teams ON teams.game_id = participants.game_id
+        AND teams.key = participants.team_key
+JOIN
+    teams enemy ON enemy.game_id = participants.game_id
+        AND enemy.key <> participants.team_key
+WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+    AND {{not excluded}}
+GROUP BY teams.key
+ORDER BY teams.key;`,
+			},
+			{
+				Title:       "Objective Counts",
+				Description: "Win rate of the selected summoners by the number of times their team took each objective",
+				Width:       24,
+				Height:      12,
+				Percent:     []string{"Win Rate"},
+				Query:       objectiveCountsQuery(),
+			},
+		},
+	},
+}
+
+// objective is a column pair of the teams table, "<column>_first" and "<column>_kill"
+type objective struct {
+	name   string
+	column string
+}
+
+var objectives = []objective{
+	{"Tower", "tower"},
+	{"Dragon", "dragon"},
+	{"Herald", "rift_herald"},
+	{"Grubs", "horde"},
+	{"Baron", "baron"},
+	{"Inhibitor", "inhibitor"},
+}
+
+// teamsOfGames joins the team of the selected summoners and the enemy team to every game
+const teamsOfGames = `FROM
+    participants
+JOIN
+    games ON participants.game_id = games.game_id
+JOIN
+    teams ON teams.game_id = participants.game_id
+        AND teams.key = participants.team_key
+JOIN
+    teams enemy ON enemy.game_id = participants.game_id
+        AND enemy.key <> participants.team_key
+WHERE participants.summoner_name IN (${SUMMONER_NAME:singlequote})
+    AND {{not excluded}}`
+
+// firstObjectivesQuery selects, for first blood and every objective, the record when the team of the
+// selected summoners took it first and when the enemy team did
+func firstObjectivesQuery() string {
+	firsts := append([]objective{{"Blood", "champion"}}, objectives...)
+	selects := []string{}
+	for _, o := range firsts {
+		selects = append(selects, fmt.Sprintf(`SELECT
+    '%[1]s' AS 'First',
+    SUM(teams.%[2]s_first) AS 'Taken',
+    ROUND(100.0 * SUM(teams.%[2]s_first AND participants.result = 'WIN') / MAX(SUM(teams.%[2]s_first), 1), 1) AS 'Taken Win Rate',
+    SUM(enemy.%[2]s_first) AS 'Conceded',
+    ROUND(100.0 * SUM(enemy.%[2]s_first AND participants.result = 'WIN') / MAX(SUM(enemy.%[2]s_first), 1), 1) AS 'Conceded Win Rate'
+%[3]s`, o.name, o.column, teamsOfGames))
+	}
+	return strings.Join(selects, "\nUNION ALL\n") + ";"
+}
+
+// objectiveCountsQuery selects the record by the number of times the team of the selected summoners
+// took each objective
+func objectiveCountsQuery() string {
+	selects := []string{}
+	for _, o := range objectives {
+		selects = append(selects, fmt.Sprintf(`SELECT
+    '%[1]s' AS 'Objective',
+    teams.%[2]s_kill AS 'Taken',
+    COUNT(*) AS 'Games',
+    ROUND(100.0 * SUM(participants.result = 'WIN') / COUNT(*), 1) AS 'Win Rate'
+%[3]s
+GROUP BY teams.%[2]s_kill`, o.name, o.column, teamsOfGames))
+	}
+	return strings.Join(selects, "\nUNION ALL\n") + ";"
+}
+
+func tableRow(t TableRow) row {
+	r := row{title: t.Title, collapsed: true}
+	for _, table := range t.Tables {
+		overrides := []any{}
+		for _, column := range table.Percent {
+			overrides = append(overrides, map[string]any{
+				"matcher":    map[string]any{"id": "byName", "options": column},
+				"properties": []any{map[string]any{"id": "unit", "value": "percent"}},
+			})
+		}
+		r.panels = append(r.panels, Panel{
+			Datasource:  &sqlite,
+			Description: table.Description,
+			FieldConfig: map[string]any{
+				"defaults": map[string]any{
+					"color":      map[string]any{"mode": "thresholds"},
+					"custom":     map[string]any{"align": "auto", "cellOptions": map[string]any{"type": "auto"}, "inspect": false},
+					"mappings":   []any{},
+					"thresholds": defaultThresholds,
+				},
+				"overrides": overrides,
+			},
+			GridPos: GridPos{H: table.Height, W: table.Width},
+			Options: map[string]any{
+				"cellHeight": "sm",
+				"footer":     map[string]any{"countRows": false, "fields": "", "reducer": []any{"sum"}, "show": false},
+				"showHeader": true,
+			},
+			PluginVersion: pluginVersion,
+			Targets:       []Target{target(table.Title, table.Query, "table", "time", "ts")},
+			Title:         table.Title,
+			Type:          "table",
+		})
+	}
+	return r
+}
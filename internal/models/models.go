@@ -4,6 +4,8 @@ package models
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -219,6 +221,280 @@ func (img Image) FullURL() string {
 	return fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/img/champion/%s", img.Version, img.Full)
 }
 
+// VersionedURL constructs the image URL for data files whose images do not carry a version, such as items and summoner spells
+func (img Image) VersionedURL(version string) string {
+	return fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/img/%s/%s", version, img.Group, img.Full)
+}
+
+// SpriteURL constructs the URL of the sprite sheet that contains the image
+func (img Image) SpriteURL(version string) string {
+	return fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/img/sprite/%s", version, img.Sprite)
+}
+
+// Sprite locates an image inside a ddragon sprite sheet
+type Sprite struct {
+	URL string // URL of the sprite sheet
+	X   int
+	Y   int
+	W   int
+	H   int
+}
+
+// DraftPick is the champion played by one participant of a game
+type DraftPick struct {
+	TeamKey    string // BLUE or RED
+	ChampionID string
+}
+
+// ItemData represents the structure of the ddragon item.json file
+type ItemData struct {
+	Type    string          `json:"type"`
+	Version string          `json:"version"`
+	Data    map[string]Item `json:"data"` // Keyed by item id
+}
+
+type Item struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Plaintext   string   `json:"plaintext"`
+	Image       Image    `json:"image"`
+	Gold        ItemGold `json:"gold"`
+	Tags        []string `json:"tags"`
+}
+
+type ItemGold struct {
+	Base        float64 `json:"base"`
+	Total       float64 `json:"total"`
+	Sell        float64 `json:"sell"`
+	Purchasable bool    `json:"purchasable"`
+}
+
+// RuneTree represents a rune path from the ddragon runesReforged.json file, e.g. Domination
+type RuneTree struct {
+	ID    int        `json:"id"`
+	Key   string     `json:"key"`
+	Icon  string     `json:"icon"`
+	Name  string     `json:"name"`
+	Slots []RuneSlot `json:"slots"`
+}
+
+type RuneSlot struct {
+	Runes []RuneDetail `json:"runes"`
+}
+
+type RuneDetail struct {
+	ID        int    `json:"id"`
+	Key       string `json:"key"`
+	Icon      string `json:"icon"`
+	Name      string `json:"name"`
+	ShortDesc string `json:"shortDesc"`
+}
+
+// RuneIconURL constructs the URL of a rune icon. Rune icons are not versioned.
+func RuneIconURL(icon string) string {
+	return "https://ddragon.leagueoflegends.com/cdn/img/" + icon
+}
+
+// SummonerSpellData represents the structure of the ddragon summoner.json file
+type SummonerSpellData struct {
+	Type    string                   `json:"type"`
+	Version string                   `json:"version"`
+	Data    map[string]SummonerSpell `json:"data"`
+}
+
+type SummonerSpell struct {
+	ID          string    `json:"id"`  // e.g. SummonerFlash
+	Key         string    `json:"key"` // Numeric id used by participant spells
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Cooldown    []float64 `json:"cooldown"`
+	Image       Image     `json:"image"`
+}
+
+// ChampionEntry is a champion as stored in the champions table
+type ChampionEntry struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Title    string   `json:"title"`
+	Tags     []string `json:"tags"`
+	ImageURL string   `json:"image_url"`
+}
+
+// ItemEntry is an item as stored in the items table
+type ItemEntry struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Plaintext string   `json:"plaintext"`
+	GoldTotal int      `json:"gold_total"`
+	Tags      []string `json:"tags"`
+	ImageURL  string   `json:"image_url"`
+}
+
+// RuneEntry is a rune or rune tree as stored in the runes table
+type RuneEntry struct {
+	ID      int    `json:"id"`
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	TreeID  int    `json:"tree_id"` // The rune's own id for trees
+	Slot    int    `json:"slot"`    // -1 for trees, 0 for keystones
+	IconURL string `json:"icon_url"`
+}
+
+// SummonerSpellEntry is a summoner spell as stored in the summoner_spells table
+type SummonerSpellEntry struct {
+	ID       int     `json:"id"`
+	Key      string  `json:"key"`
+	Name     string  `json:"name"`
+	Cooldown float64 `json:"cooldown"`
+	ImageURL string  `json:"image_url"`
+}
+
+// NamedID is a champion, item, summoner spell or rune id with its name from the static data.
+// Name is empty when no static data is stored for the id.
+type NamedID struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (n NamedID) String() string {
+	if n.Name == "" {
+		return fmt.Sprintf("#%d", n.ID)
+	}
+	return n.Name
+}
+
+// Game flags set when a game is stored. Stats leave out games with the flags in the stats.exclude setting.
+const (
+	FlagRemake          = "remake"           // Reported as a remake by op.gg
+	FlagEarlySurrender  = "early_surrender"  // Ended before 20 minutes
+	FlagLeaverSuspected = "leaver_suspected" // A participant earned far less gold than their teammates
+	FlagIncompleteData  = "incomplete_data"  // Teams or participants are missing
+)
+
+// GameFlags lists every game flag
+var GameFlags = []string{FlagRemake, FlagEarlySurrender, FlagLeaverSuspected, FlagIncompleteData}
+
+// GameSummary is a game as seen by one summoner
+type GameSummary struct {
+	GameID     string    `json:"game_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	GameLength int       `json:"game_length"` // Seconds
+	Patch      string    `json:"patch"`
+	IsRemake   bool      `json:"is_remake"`
+	Flags      []string  `json:"flags"`
+	Summoner   string    `json:"summoner"`
+	Champion   NamedID   `json:"champion"`
+	Position   string    `json:"position"`
+	Result     string    `json:"result"`
+	Kills      int       `json:"kills"`
+	Deaths     int       `json:"deaths"`
+	Assists    int       `json:"assists"`
+}
+
+// Scoreboard is the full result of a stored game
+type Scoreboard struct {
+	GameID     string           `json:"game_id"`
+	CreatedAt  time.Time        `json:"created_at"`
+	GameLength int              `json:"game_length"` // Seconds
+	Version    string           `json:"version"`
+	Patch      string           `json:"patch"`
+	IsRemake   bool             `json:"is_remake"`
+	Flags      []string         `json:"flags"`
+	Teams      []TeamScoreboard `json:"teams"`
+}
+
+// TeamScoreboard holds the objectives, bans and players of one team
+type TeamScoreboard struct {
+	Key        string             `json:"key"` // BLUE or RED
+	IsWin      bool               `json:"is_win"`
+	Kills      int                `json:"kills"`
+	Deaths     int                `json:"deaths"`
+	Assists    int                `json:"assists"`
+	GoldEarned int                `json:"gold_earned"`
+	Dragons    int                `json:"dragons"`
+	Barons     int                `json:"barons"`
+	Heralds    int                `json:"heralds"`
+	Hordes     int                `json:"hordes"` // Void grubs
+	Towers     int                `json:"towers"`
+	Inhibitors int                `json:"inhibitors"`
+	Firsts     []string           `json:"firsts"` // Objectives the team took first, e.g. "blood" or "tower"
+	Bans       []NamedID          `json:"bans"`
+	Players    []PlayerScoreboard `json:"players"`
+}
+
+// PlayerScoreboard is the result of one participant
+type PlayerScoreboard struct {
+	SummonerName  string    `json:"summoner_name"`
+	Champion      NamedID   `json:"champion"`
+	Position      string    `json:"position"`
+	Kills         int       `json:"kills"`
+	Deaths        int       `json:"deaths"`
+	Assists       int       `json:"assists"`
+	CS            *int      `json:"cs"` // nil for games stored before creep score was recorded
+	GoldEarned    int       `json:"gold_earned"`
+	DamageDealt   int       `json:"damage_dealt"`
+	DamageTaken   int       `json:"damage_taken"`
+	VisionScore   int       `json:"vision_score"`
+	OPScoreRank   int       `json:"op_score_rank"`
+	Items         []NamedID `json:"items"`
+	Spells        []NamedID `json:"spells"`
+	PrimaryRune   NamedID   `json:"primary_rune"`
+	SecondaryTree NamedID   `json:"secondary_tree"`
+}
+
+// Patch returns the major.minor part of a game or ddragon version, e.g. "14.24" for "14.24.644.2327"
+func Patch(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// CompareVersions compares dotted versions numerically and returns -1, 0 or 1
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// ChampionVersion is a champion as stored in champion_versions for one ddragon version
+type ChampionVersion struct {
+	ChampionID string
+	Version    string
+	Name       string
+	Title      string
+	Tags       []string
+	Partype    string
+	Info       ChampionInfo
+	Stats      map[string]float64
+}
+
+// ItemVersion is an item as stored in item_versions for one ddragon version
+type ItemVersion struct {
+	ItemID    int
+	Version   string
+	Name      string
+	Plaintext string
+	GoldBase  int
+	GoldTotal int
+	Tags      []string
+}
+
 // Game represents a game entry to be inserted into the database
 type Game struct {
 	ID               string    `json:"id"`
@@ -234,6 +510,14 @@ type Game struct {
 	Meta             GameMeta  `json:"meta"`
 }
 
+// Patch returns the patch the game was played on, used to find the matching static data
+func (g Game) Patch() string {
+	if g.Version != "" {
+		return Patch(g.Version)
+	}
+	return Patch(g.MetaVersion)
+}
+
 // GameMeta holds the meta information with time.Time fields
 type GameMeta struct {
 	FirstGameCreatedAt time.Time
@@ -272,6 +556,63 @@ type TeamStat struct {
 }
 
 type FetchRecord struct {
-	FetchType string // "GAMES" or "CHAMPIONS"
+	FetchType string    // "GAMES" or "CHAMPIONS"
 	LastFetch time.Time // The last time the records were fetched
-}
\ No newline at end of file
+}
+
+// Goal periods
+const (
+	PeriodWeek  = "week"  // Since Monday
+	PeriodMonth = "month" // Since the first of the month
+	PeriodAll   = "all"
+)
+
+// Goal is a target for a tracked summoner, either for the average of a metric, e.g. average deaths < 5,
+// or for the share of games meeting it, e.g. vision score >= 30 in 70% of games
+type Goal struct {
+	ID         int64     `json:"id"`
+	SummonerID string    `json:"summoner_id"`
+	Metric     string    `json:"metric"`
+	Comparison string    `json:"comparison"` // >=, >, <= or <
+	Target     float64   `json:"target"`
+	Share      float64   `json:"share,omitempty"`    // 0 to 1, 0 compares the average
+	Position   string    `json:"position,omitempty"` // Empty counts every position
+	Period     string    `json:"period"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (g Goal) String() string {
+	var s string
+	if g.Share > 0 {
+		s = fmt.Sprintf("%s %s %g in %.0f%% of games", g.Metric, g.Comparison, g.Target, g.Share*100)
+	} else {
+		s = fmt.Sprintf("average %s %s %g", g.Metric, g.Comparison, g.Target)
+	}
+	if g.Position != "" {
+		s += " on " + g.Position
+	}
+	switch g.Period {
+	case PeriodWeek:
+		s += " this week"
+	case PeriodMonth:
+		s += " this month"
+	}
+	return s
+}
+
+// GoalProgress is a goal and its latest evaluation
+type GoalProgress struct {
+	Goal        Goal       `json:"goal"`
+	Description string     `json:"description"`
+	EvaluatedAt *time.Time `json:"evaluated_at"` // nil until the goal is evaluated
+	Games       int        `json:"games"`
+	Value       float64    `json:"value"` // The average, or the share of games meeting the target
+	Met         bool       `json:"met"`
+}
+
+// APIToken is a token created with the "tokens create" command. The token itself is never stored.
+type APIToken struct {
+	Name      string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,209 @@
+// internal/patches/diff.go
+package patches
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"opggvisualizer/internal/db"
+	"opggvisualizer/internal/models"
+)
+
+// Change is a single field that differs between two versions
+type Change struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Status of an entry in a Diff
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+type ChampionDiff struct {
+	ChampionID string   `json:"champion_id"`
+	Name       string   `json:"name"`
+	Status     string   `json:"status"`
+	Changes    []Change `json:"changes,omitempty"`
+}
+
+type ItemDiff struct {
+	ItemID  int      `json:"item_id"`
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Diff lists the champions and items that changed between two ddragon versions
+type Diff struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Champions []ChampionDiff `json:"champions"`
+	Items     []ItemDiff     `json:"items"`
+}
+
+// Compare diffs the stored static data of two ddragon versions or patches
+func Compare(ctx context.Context, database *db.Database, from, to string) (*Diff, error) {
+	fromVersion, err := database.ResolveStaticVersion(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := database.ResolveStaticVersion(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromChampions, err := database.GetChampionVersions(ctx, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	toChampions, err := database.GetChampionVersions(ctx, toVersion)
+	if err != nil {
+		return nil, err
+	}
+	fromItems, err := database.GetItemVersions(ctx, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	toItems, err := database.GetItemVersions(ctx, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{
+		From:      fromVersion,
+		To:        toVersion,
+		Champions: diffChampions(fromChampions, toChampions),
+		Items:     diffItems(fromItems, toItems),
+	}
+	return diff, nil
+}
+
+func diffChampions(from, to map[string]models.ChampionVersion) []ChampionDiff {
+	diffs := []ChampionDiff{}
+	for id, old := range from {
+		current, ok := to[id]
+		if !ok {
+			diffs = append(diffs, ChampionDiff{ChampionID: id, Name: old.Name, Status: Removed})
+			continue
+		}
+
+		var changes []Change
+		changes = appendChange(changes, "name", old.Name, current.Name)
+		changes = appendChange(changes, "title", old.Title, current.Title)
+		changes = appendChange(changes, "tags", strings.Join(old.Tags, ","), strings.Join(current.Tags, ","))
+		changes = appendChange(changes, "partype", old.Partype, current.Partype)
+		changes = appendChange(changes, "attack", formatNumber(old.Info.Attack), formatNumber(current.Info.Attack))
+		changes = appendChange(changes, "defense", formatNumber(old.Info.Defense), formatNumber(current.Info.Defense))
+		changes = appendChange(changes, "magic", formatNumber(old.Info.Magic), formatNumber(current.Info.Magic))
+		changes = appendChange(changes, "difficulty", formatNumber(old.Info.Difficulty), formatNumber(current.Info.Difficulty))
+		for _, stat := range unionKeys(old.Stats, current.Stats) {
+			oldValue, oldOK := old.Stats[stat]
+			newValue, newOK := current.Stats[stat]
+			changes = appendChange(changes, "stats."+stat, formatOptional(oldValue, oldOK), formatOptional(newValue, newOK))
+		}
+
+		if len(changes) > 0 {
+			diffs = append(diffs, ChampionDiff{ChampionID: id, Name: current.Name, Status: Changed, Changes: changes})
+		}
+	}
+	for id, current := range to {
+		if _, ok := from[id]; !ok {
+			diffs = append(diffs, ChampionDiff{ChampionID: id, Name: current.Name, Status: Added})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
+
+func diffItems(from, to map[int]models.ItemVersion) []ItemDiff {
+	diffs := []ItemDiff{}
+	for id, old := range from {
+		current, ok := to[id]
+		if !ok {
+			diffs = append(diffs, ItemDiff{ItemID: id, Name: old.Name, Status: Removed})
+			continue
+		}
+
+		var changes []Change
+		changes = appendChange(changes, "name", old.Name, current.Name)
+		changes = appendChange(changes, "plaintext", old.Plaintext, current.Plaintext)
+		changes = appendChange(changes, "gold_base", strconv.Itoa(old.GoldBase), strconv.Itoa(current.GoldBase))
+		changes = appendChange(changes, "gold_total", strconv.Itoa(old.GoldTotal), strconv.Itoa(current.GoldTotal))
+		changes = appendChange(changes, "tags", strings.Join(old.Tags, ","), strings.Join(current.Tags, ","))
+
+		if len(changes) > 0 {
+			diffs = append(diffs, ItemDiff{ItemID: id, Name: current.Name, Status: Changed, Changes: changes})
+		}
+	}
+	for id, current := range to {
+		if _, ok := from[id]; !ok {
+			diffs = append(diffs, ItemDiff{ItemID: id, Name: current.Name, Status: Added})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].ItemID < diffs[j].ItemID })
+	return diffs
+}
+
+func appendChange(changes []Change, field, from, to string) []Change {
+	if from == to {
+		return changes
+	}
+	return append(changes, Change{Field: field, From: from, To: to})
+}
+
+func unionKeys(a, b map[string]float64) []string {
+	keys := make([]string, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatOptional(v float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return formatNumber(v)
+}
+
+// String renders the diff as plain text
+func (d *Diff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Changes from %s to %s\n", d.From, d.To)
+
+	fmt.Fprintf(&b, "\nChampions (%d)\n", len(d.Champions))
+	for _, c := range d.Champions {
+		fmt.Fprintf(&b, "  %-16s %s\n", c.Name, c.Status)
+		for _, change := range c.Changes {
+			fmt.Fprintf(&b, "      %-24s %s -> %s\n", change.Field, change.From, change.To)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nItems (%d)\n", len(d.Items))
+	for _, i := range d.Items {
+		fmt.Fprintf(&b, "  %-6d %-24s %s\n", i.ItemID, i.Name, i.Status)
+		for _, change := range i.Changes {
+			fmt.Fprintf(&b, "      %-24s %s -> %s\n", change.Field, change.From, change.To)
+		}
+	}
+	return b.String()
+}
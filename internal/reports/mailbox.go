@@ -0,0 +1,104 @@
+// internal/reports/mailbox.go
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mailbox is a stand-in SMTP server that writes every message to out. It is used to try the report
+// emails locally before pointing reports.email at a real server. It accepts any credentials and offers
+// no TLS, so it is reached on localhost where net/smtp allows PLAIN authentication without TLS.
+type Mailbox struct {
+	out      io.Writer
+	mu       sync.Mutex
+	received int
+}
+
+// NewMailbox returns a mailbox writing the messages it receives to out
+func NewMailbox(out io.Writer) *Mailbox {
+	return &Mailbox{out: out}
+}
+
+// Serve answers the SMTP connections accepted on l until l is closed
+func (mb *Mailbox) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go mb.serveConn(conn)
+	}
+}
+
+// serveConn runs one SMTP session, supporting the commands net/smtp sends
+func (mb *Mailbox) serveConn(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	var from string
+	var to []string
+	tp.PrintfLine("220 opggvisualizer mailbox")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			tp.PrintfLine("250-opggvisualizer")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "HELO", "NOOP":
+			tp.PrintfLine("250 OK")
+		case "AUTH":
+			tp.PrintfLine("235 Authenticated")
+		case "MAIL":
+			from, to = smtpPath(arg), nil
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			to = append(to, smtpPath(arg))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 End the message with a line holding a single dot")
+			msg, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mb.record(from, to, msg)
+			tp.PrintfLine("250 OK")
+		case "RSET":
+			from, to = "", nil
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func (mb *Mailbox) record(from string, to []string, msg []byte) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.received++
+	fmt.Fprintf(mb.out, "%s message %d: from %s to %s\n", time.Now().Format("15:04:05"), mb.received, from, strings.Join(to, ", "))
+	fmt.Fprintf(mb.out, "%s\n", msg)
+}
+
+// smtpPath returns the address of a "FROM:<address>" or "TO:<address>" argument
+func smtpPath(arg string) string {
+	_, path, _ := strings.Cut(arg, ":")
+	path, _, _ = strings.Cut(strings.TrimSpace(path), " ") // Leave out parameters such as BODY=8BITMIME
+	return strings.Trim(path, "<>")
+}
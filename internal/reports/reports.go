@@ -0,0 +1,186 @@
+// internal/reports/reports.go
+package reports
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"math"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/db"
+	"opggvisualizer/internal/stats"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// funcs format the values of a stats.WeeklyReport in the templates
+var funcs = map[string]any{
+	"date":    func(t time.Time) string { return t.Format("Mon 2 Jan 2006") },
+	"lastDay": func(end time.Time) string { return end.AddDate(0, 0, -1).Format("Mon 2 Jan 2006") },
+	"percent": func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) },
+	"points":  func(v float64) string { return fmt.Sprintf("%+.0f pts", v*100) },
+	"fixed":   func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"signed":  func(v float64) string { return fmt.Sprintf("%+.2f", v) },
+	"number": func(v float64) string {
+		if v == math.Trunc(v) {
+			return fmt.Sprintf("%.0f", v)
+		}
+		return fmt.Sprintf("%.2f", v)
+	},
+	"signedInt":  func(v int) string { return fmt.Sprintf("%+d", v) },
+	"gameLength": func(seconds int) string { return fmt.Sprintf("%d:%02d", seconds/60, seconds%60) },
+	"result": func(result string) string {
+		if result == "WIN" {
+			return "Won"
+		}
+		return "Lost"
+	},
+	// trend is the CSS class of a change
+	"trend": func(v float64) string {
+		switch {
+		case v > 0.005:
+			return "up"
+		case v < -0.005:
+			return "down"
+		}
+		return ""
+	},
+}
+
+var (
+	htmlTemplate     = htmltemplate.Must(htmltemplate.New("weekly.html").Funcs(funcs).ParseFS(templateFS, "templates/weekly.html"))
+	markdownTemplate = template.Must(template.New("weekly.md").Funcs(funcs).ParseFS(templateFS, "templates/weekly.md"))
+)
+
+// Weekly builds the report of a configured summoner, chosen as in stats.SummonerFilter, for the week containing start
+func Weekly(ctx context.Context, cfg *config.Config, database *db.Database, summoner string, start time.Time) (*stats.WeeklyReport, error) {
+	filter, err := stats.SummonerFilter(cfg, summoner)
+	if err != nil {
+		return nil, err
+	}
+	return stats.ComputeWeeklyReport(ctx, database, filter, start)
+}
+
+// Render writes report as a self-contained document in one of config.ReportFormats
+func Render(w io.Writer, format string, report *stats.WeeklyReport) error {
+	switch format {
+	case config.ReportFormatHTML:
+		return htmlTemplate.Execute(w, report)
+	case config.ReportFormatMarkdown:
+		return markdownTemplate.Execute(w, report)
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// Filename names the file of a report, e.g. "weekly-<summoner id>-2024-11-04.html"
+func Filename(format string, report *stats.WeeklyReport) string {
+	ext := ".html"
+	if format == config.ReportFormatMarkdown {
+		ext = ".md"
+	}
+	id := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, report.SummonerID)
+	return "weekly-" + id + "-" + report.Start.Format("2006-01-02") + ext
+}
+
+// Published describes where the report of one summoner was delivered
+type Published struct {
+	SummonerID string    `json:"summoner_id"`
+	Start      time.Time `json:"start"`
+	Path       string    `json:"path"`                 // File written in reports.dir
+	EmailedTo  []string  `json:"emailed_to,omitempty"` // Empty when email is not configured
+}
+
+// Publish builds the report of every configured summoner for the week containing start, writes it to
+// reports.dir and emails it when reports.email is configured
+func Publish(ctx context.Context, cfg *config.Config, database *db.Database, start time.Time) ([]Published, error) {
+	if err := os.MkdirAll(cfg.Reports.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	published := []Published{}
+	var errs []error
+	for _, summoner := range cfg.Summoners {
+		report, err := Weekly(ctx, cfg, database, summoner.ID, start)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("summoner %s: %w", summoner.ID, err))
+			continue
+		}
+		var body bytes.Buffer
+		if err := Render(&body, cfg.Reports.Format, report); err != nil {
+			errs = append(errs, fmt.Errorf("summoner %s: %w", summoner.ID, err))
+			continue
+		}
+
+		p := Published{SummonerID: summoner.ID, Start: report.Start, Path: filepath.Join(cfg.Reports.Dir, Filename(cfg.Reports.Format, report))}
+		if err := os.WriteFile(p.Path, body.Bytes(), 0o644); err != nil {
+			errs = append(errs, fmt.Errorf("summoner %s: failed to write report: %w", summoner.ID, err))
+			continue
+		}
+		if cfg.Reports.Email.SMTPAddr != "" {
+			if err := Email(cfg.Reports.Email, cfg.Reports.Format, report, body.Bytes()); err != nil {
+				errs = append(errs, fmt.Errorf("summoner %s: %w", summoner.ID, err))
+			} else {
+				p.EmailedTo = cfg.Reports.Email.To
+			}
+		}
+		published = append(published, p)
+	}
+	return published, errors.Join(errs...)
+}
+
+// Email sends a rendered report to the configured recipients
+func Email(cfg config.EmailConfig, format string, report *stats.WeeklyReport, body []byte) error {
+	contentType := "text/html; charset=UTF-8"
+	if format == config.ReportFormatMarkdown {
+		contentType = "text/markdown; charset=UTF-8"
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	subject := fmt.Sprintf("Weekly report for %s, week of %s", report.Summoner, report.Start.Format("2 Jan 2006"))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&msg, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&msg)
+	if _, err := qp.Write(body); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+
+	// PlainAuth refuses to send the password over a connection without TLS, except to localhost.
+	// SendMail upgrades the connection when the server offers STARTTLS.
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(cfg.SMTPAddr)
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	if err := smtp.SendMail(cfg.SMTPAddr, auth, cfg.From, cfg.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to email report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/db"
+	"opggvisualizer/internal/models"
+)
+
+// syncBuffer is written by the mailbox while the test reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// storeGame stores a game of the week of 2024-11-04 played by "Me" as Ahri
+func storeGame(t *testing.T, database *db.Database, id string, day int, win bool) {
+	t.Helper()
+	ctx := context.Background()
+	game := models.Game{ID: id, CreatedAt: time.Date(2024, 11, day, 18, 0, 0, 0, time.UTC), GameLengthSecond: 1800, Version: "14.20.1"}
+	if err := database.InsertGame(ctx, game); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"BLUE", "RED"} {
+		team := models.Team{Key: key, GameStat: models.TeamStat{IsWin: (key == "BLUE") == win, GoldEarned: 50000, Kill: 20}}
+		if err := database.InsertTeam(ctx, id, team); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range 10 {
+		p := models.Participant{ParticipantID: i + 1, ChampionID: 103, TeamKey: "BLUE", Position: "MID"}
+		p.Summoner.Name, p.Summoner.SummonerID = fmt.Sprintf("player %d", i+1), fmt.Sprintf("sid-%d", i+1)
+		if i == 0 {
+			p.Summoner.Name, p.Summoner.SummonerID = "Me", "sid-me"
+		}
+		if i >= 5 {
+			p.TeamKey = "RED"
+		}
+		p.Stats.Result = "LOSE"
+		if (p.TeamKey == "BLUE") == win {
+			p.Stats.Result = "WIN"
+		}
+		p.Stats.Kill, p.Stats.Death, p.Stats.Assist, p.Stats.GoldEarned = 5, 2, 6, 10000
+		if err := database.InsertParticipant(ctx, id, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := database.UpdateParticipantMetrics(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.ClassifyGame(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishEmailsReport(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.Open(ctx, filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.InsertChampion(ctx, models.Champion{ID: "Ahri", Key: "103", Name: "Ahri"}); err != nil {
+		t.Fatal(err)
+	}
+	storeGame(t, database, "game-1", 5, true)
+	storeGame(t, database, "game-2", 6, false)
+	storeGame(t, database, "game-3", 7, true)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	var mails syncBuffer
+	go NewMailbox(&mails).Serve(listener)
+
+	cfg := &config.Config{
+		Summoners: []config.Summoner{{ID: "sid-me", Name: "Me"}},
+		Reports: config.ReportsConfig{
+			Format: config.ReportFormatHTML,
+			Dir:    t.TempDir(),
+			Email: config.EmailConfig{
+				SMTPAddr: listener.Addr().String(),
+				Username: "reports", // PLAIN authentication is allowed without TLS on localhost
+				Password: "secret",
+				From:     "reports@example.com",
+				To:       []string{"me@example.com", "coach@example.com"},
+			},
+		},
+	}
+	published, err := Publish(ctx, cfg, database, time.Date(2024, 11, 6, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(published) != 1 || len(published[0].EmailedTo) != 2 {
+		t.Fatalf("published = %+v, want the report of sid-me emailed to both recipients", published)
+	}
+
+	if want := filepath.Join(cfg.Reports.Dir, "weekly-sid-me-2024-11-04.html"); published[0].Path != want {
+		t.Errorf("report written to %s, want %s", published[0].Path, want)
+	}
+	report, err := os.ReadFile(published[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "Ahri") || !strings.Contains(string(report), "67%") {
+		t.Errorf("report does not show the 2 wins in 3 games as Ahri:\n%s", report)
+	}
+
+	got := mails.String()
+	for _, want := range []string{
+		"message 1: from reports@example.com to me@example.com, coach@example.com",
+		"Subject: Weekly report for Me, week of 4 Nov 2024",
+		"Content-Type: text/html; charset=UTF-8",
+		"Ahri",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("mailbox did not receive %q:\n%s", want, got)
+		}
+	}
+}
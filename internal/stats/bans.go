@@ -0,0 +1,143 @@
+// internal/stats/bans.go
+package stats
+
+import (
+	"context"
+	"fmt"
+
+	"opggvisualizer/internal/db"
+)
+
+// BannedChampion is how often a champion was banned by one side in the games of a summoner
+type BannedChampion struct {
+	ChampionID string  `json:"champion_id"`
+	Champion   string  `json:"champion"`
+	Bans       int     `json:"bans"`
+	BanRate    float64 `json:"ban_rate"`   // Share of the summoner's games, 0 to 1
+	PickOrder  float64 `json:"pick_order"` // Average position in the team's bans, 1 to 5
+	Record     Record  `json:"record"`     // Record of the summoner in the games the champion was banned
+}
+
+// BanImpact compares the record of a summoner in the games a champion was banned, by either team, with the games it was open
+type BanImpact struct {
+	ChampionID string `json:"champion_id"`
+	Champion   string `json:"champion"`
+	Banned     Record `json:"banned"`
+	Open       Record `json:"open"`
+}
+
+// Bans summarizes the bans in the games of a summoner
+type Bans struct {
+	Overall   Record           `json:"overall"`
+	AgainstUs []BannedChampion `json:"against_us"` // Banned by the enemy team
+	OurBans   []BannedChampion `json:"our_bans"`   // Banned by the summoner's team
+	Impact    []BanImpact      `json:"impact"`
+}
+
+// ComputeBans aggregates the bans of both teams in the games matched by filter.
+// Champions banned in fewer than minGames games are left out.
+func ComputeBans(ctx context.Context, database *db.Database, filter Filter, minGames int) (*Bans, error) {
+	where, args := filter.where()
+	bans := &Bans{}
+
+	var games, wins int
+	if err := database.Conn.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(p.result = 'WIN'), 0)
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	WHERE `+where+`;`, args...).Scan(&games, &wins); err != nil {
+		return nil, fmt.Errorf("failed to query overall record: %w", err)
+	}
+	bans.Overall = newRecord(games, wins)
+
+	var err error
+	if bans.AgainstUs, err = teamBans(ctx, database, where, args, filter.locale(), "<>", games, minGames); err != nil {
+		return nil, err
+	}
+	if bans.OurBans, err = teamBans(ctx, database, where, args, filter.locale(), "=", games, minGames); err != nil {
+		return nil, err
+	}
+	if bans.Impact, err = banImpact(ctx, database, where, args, filter.locale(), bans.Overall, minGames); err != nil {
+		return nil, err
+	}
+	return bans, nil
+}
+
+// teamBans aggregates the bans of one team. comparison is "=" for the summoner's team and "<>" for the enemy team.
+func teamBans(ctx context.Context, database *db.Database, where string, args []any, locale, comparison string, games, minGames int) ([]BannedChampion, error) {
+	rows, err := database.Conn.QueryContext(ctx, `SELECT
+		CAST(b.champion_id AS TEXT),
+		COALESCE(ct.name, c.name, 'Champion ' || b.champion_id),
+		COUNT(*),
+		SUM(p.result = 'WIN'),
+		AVG(b.pick_order)
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	JOIN teams t ON t.game_id = p.game_id AND t.key `+comparison+` p.team_key
+	JOIN team_banned_champions b ON b.team_id = t.team_id AND b.champion_id IS NOT NULL
+	LEFT JOIN champions c ON c.champion_id = CAST(b.champion_id AS TEXT)
+	LEFT JOIN champion_translations ct ON ct.champion_id = c.champion_id AND ct.locale = ?
+	WHERE `+where+`
+	GROUP BY b.champion_id
+	HAVING COUNT(*) >= ?
+	ORDER BY COUNT(*) DESC, 2;`, withArgs(withArgs([]any{locale}, args...), minGames)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team bans: %w", err)
+	}
+	defer rows.Close()
+
+	result := []BannedChampion{}
+	for rows.Next() {
+		var b BannedChampion
+		var wins int
+		if err := rows.Scan(&b.ChampionID, &b.Champion, &b.Bans, &wins, &b.PickOrder); err != nil {
+			return nil, err
+		}
+		b.Record = newRecord(b.Bans, wins)
+		if games > 0 {
+			b.BanRate = float64(b.Bans) / float64(games)
+		}
+		result = append(result, b)
+	}
+	return result, rows.Err()
+}
+
+// banImpact splits the overall record per champion into the games it was banned and the games it was open
+func banImpact(ctx context.Context, database *db.Database, where string, args []any, locale string, overall Record, minGames int) ([]BanImpact, error) {
+	// A champion banned by both teams counts once for the game
+	rows, err := database.Conn.QueryContext(ctx, `SELECT
+		CAST(banned.champion_id AS TEXT),
+		COALESCE(ct.name, c.name, 'Champion ' || banned.champion_id),
+		COUNT(*),
+		SUM(p.result = 'WIN')
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	JOIN (
+		SELECT DISTINCT t.game_id, b.champion_id
+		FROM teams t
+		JOIN team_banned_champions b ON b.team_id = t.team_id
+		WHERE b.champion_id IS NOT NULL
+	) banned ON banned.game_id = p.game_id
+	LEFT JOIN champions c ON c.champion_id = CAST(banned.champion_id AS TEXT)
+	LEFT JOIN champion_translations ct ON ct.champion_id = c.champion_id AND ct.locale = ?
+	WHERE `+where+`
+	GROUP BY banned.champion_id
+	HAVING COUNT(*) >= ?
+	ORDER BY COUNT(*) DESC, 2;`, withArgs(withArgs([]any{locale}, args...), minGames)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ban impact: %w", err)
+	}
+	defer rows.Close()
+
+	result := []BanImpact{}
+	for rows.Next() {
+		var i BanImpact
+		var games, wins int
+		if err := rows.Scan(&i.ChampionID, &i.Champion, &games, &wins); err != nil {
+			return nil, err
+		}
+		i.Banned = newRecord(games, wins)
+		i.Open = newRecord(overall.Games-games, overall.Wins-wins)
+		result = append(result, i)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,107 @@
+// internal/stats/champions.go
+package stats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"opggvisualizer/internal/db"
+)
+
+// ChampionStats is the performance of a summoner on one champion
+type ChampionStats struct {
+	ChampionID  string  `json:"champion_id"`
+	Champion    string  `json:"champion"`
+	Games       int     `json:"games"`
+	Wins        int     `json:"wins"`
+	WinRate     float64 `json:"win_rate"` // 0 to 1
+	Kills       float64 `json:"kills"`    // Per game
+	Deaths      float64 `json:"deaths"`   // Per game
+	Assists     float64 `json:"assists"`  // Per game
+	KDA         float64 `json:"kda"`      // Per game average of participant_metrics
+	OPScoreRank float64 `json:"op_score_rank"`
+	CSPerMinute float64 `json:"cs_per_minute"` // Per game average, only games stored with creep score are counted
+	VisionScore float64 `json:"vision_score"`  // Per game
+
+	KillParticipation float64 `json:"kill_participation"` // Per game average of participant_metrics, 0 to 1
+	DamageShare       float64 `json:"damage_share"`
+	GoldShare         float64 `json:"gold_share"`
+}
+
+// Champions aggregates the games matched by filter per champion, most played first
+func Champions(ctx context.Context, database *db.Database, filter Filter) ([]ChampionStats, error) {
+	where, args := filter.where()
+	query := `SELECT
+		p.champion_id,
+		COALESCE(ct.name, c.name, 'Champion ' || p.champion_id),
+		COUNT(*),
+		SUM(p.result = 'WIN'),
+		AVG(p.kills),
+		AVG(p.deaths),
+		AVG(p.assists),
+		COALESCE(AVG(m.kda), 0),
+		AVG(p.op_score_rank),
+		COALESCE(AVG(m.cs_per_minute), 0),
+		AVG(p.vision_score),
+		COALESCE(AVG(m.kill_participation), 0),
+		COALESCE(AVG(m.damage_share), 0),
+		COALESCE(AVG(m.gold_share), 0)
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	LEFT JOIN participant_metrics m ON m.participant_id = p.id
+	LEFT JOIN champions c ON c.champion_id = p.champion_id
+	LEFT JOIN champion_translations ct ON ct.champion_id = c.champion_id AND ct.locale = ?
+	WHERE ` + where + `
+	GROUP BY p.champion_id
+	ORDER BY COUNT(*) DESC, 2;`
+
+	rows, err := database.Conn.QueryContext(ctx, query, withArgs([]any{filter.locale()}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query champion stats: %w", err)
+	}
+	defer rows.Close()
+
+	champions := []ChampionStats{}
+	for rows.Next() {
+		var c ChampionStats
+		if err := rows.Scan(&c.ChampionID, &c.Champion, &c.Games, &c.Wins, &c.Kills, &c.Deaths, &c.Assists,
+			&c.KDA, &c.OPScoreRank, &c.CSPerMinute, &c.VisionScore,
+			&c.KillParticipation, &c.DamageShare, &c.GoldShare); err != nil {
+			return nil, err
+		}
+		c.WinRate = float64(c.Wins) / float64(c.Games)
+		champions = append(champions, c)
+	}
+	return champions, rows.Err()
+}
+
+// ChampionSortKeys are the columns champion stats can be sorted by
+var ChampionSortKeys = []string{"games", "winrate", "kda", "opscore", "cs", "vision", "name"}
+
+// SortChampions orders champion stats by key, best first. Ties keep the most played champion first.
+func SortChampions(champions []ChampionStats, key string) error {
+	var less func(a, b ChampionStats) bool
+	switch key {
+	case "games":
+		less = func(a, b ChampionStats) bool { return a.Games > b.Games }
+	case "winrate":
+		less = func(a, b ChampionStats) bool { return a.WinRate > b.WinRate }
+	case "kda":
+		less = func(a, b ChampionStats) bool { return a.KDA > b.KDA }
+	case "opscore":
+		// Rank 1 is the best player of the game
+		less = func(a, b ChampionStats) bool { return a.OPScoreRank < b.OPScoreRank }
+	case "cs":
+		less = func(a, b ChampionStats) bool { return a.CSPerMinute > b.CSPerMinute }
+	case "vision":
+		less = func(a, b ChampionStats) bool { return a.VisionScore > b.VisionScore }
+	case "name":
+		less = func(a, b ChampionStats) bool { return a.Champion < b.Champion }
+	default:
+		return fmt.Errorf("invalid sort %q, expected one of %s", key, strings.Join(ChampionSortKeys, ", "))
+	}
+	sort.SliceStable(champions, func(i, j int) bool { return less(champions[i], champions[j]) })
+	return nil
+}
@@ -0,0 +1,151 @@
+// internal/stats/filter.go
+package stats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"opggvisualizer/internal/config"
+)
+
+// Filter selects the games of one summoner that are aggregated
+type Filter struct {
+	SummonerID   string    // op.gg summoner id
+	SummonerName string    // Also matched, games stored before summoner ids were recorded only have the name. Defaults to SummonerID
+	Since        time.Time // Zero includes every game
+	Until        time.Time // Games starting at or after Until are left out. Zero has no upper bound
+	Position     string    // TOP, JUNGLE, MID, ADC or SUPPORT. Empty includes every position
+	Exclude      []string  // Games with any of these flags are left out, see models.GameFlags
+	Locale       string    // ddragon locale of the champion names, e.g. "de_DE". Empty or untranslated names are en_US
+}
+
+// SummonerFilter selects a configured summoner by id or name. Unknown values are used as the id as is.
+// The summoner may be left empty when only one is configured. The games excluded by the stats.exclude
+// setting are left out.
+func SummonerFilter(cfg *config.Config, summoner string) (Filter, error) {
+	filter := Filter{SummonerID: summoner, Exclude: cfg.Stats.Exclude}
+	if summoner == "" {
+		if len(cfg.Summoners) != 1 {
+			return Filter{}, fmt.Errorf("%d summoners are configured, choose one by id or name", len(cfg.Summoners))
+		}
+		filter.SummonerID, filter.SummonerName = cfg.Summoners[0].ID, cfg.Summoners[0].Name
+		return filter, nil
+	}
+	for _, s := range cfg.Summoners {
+		if s.ID == summoner || (s.Name != "" && strings.EqualFold(s.Name, summoner)) {
+			filter.SummonerID, filter.SummonerName = s.ID, s.Name
+			return filter, nil
+		}
+	}
+	return filter, nil
+}
+
+// Positions reported by op.gg
+var Positions = []string{"TOP", "JUNGLE", "MID", "ADC", "SUPPORT"}
+
+// ParseSince parses a date such as "2024-11-01", or a period before now such as "30d", "2w" or "12h"
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	// time.ParseDuration has no day or week units
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, err := strconv.Atoi(strings.TrimSuffix(value, suffix)); err == nil && strings.HasSuffix(value, suffix) && n >= 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q, expected a date such as 2024-11-01 or a period such as 30d", value)
+}
+
+// ParsePosition normalizes a position flag
+func ParsePosition(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	position := strings.ToUpper(value)
+	for _, p := range Positions {
+		if p == position {
+			return position, nil
+		}
+	}
+	return "", fmt.Errorf("invalid position %q, expected one of %s", value, strings.Join(Positions, ", "))
+}
+
+// where returns the conditions selecting the participant rows of the filter, for a
+// query over participants p joined with games g
+func (f Filter) where() (string, []any) {
+	conditions := []string{"(p.summoner_id = ? OR p.summoner_name = ?)"}
+	name := f.SummonerName
+	if name == "" {
+		name = f.SummonerID
+	}
+	args := []any{f.SummonerID, name}
+	if len(f.Exclude) > 0 {
+		placeholders := make([]string, len(f.Exclude))
+		for i, flag := range f.Exclude {
+			placeholders[i] = "?"
+			args = append(args, flag)
+		}
+		conditions = append(conditions, excludeCondition("g.game_id", placeholders))
+	}
+	if !f.Since.IsZero() {
+		// created_at is stored as RFC 3339 in UTC, which sorts as text
+		conditions = append(conditions, "g.created_at >= ?")
+		args = append(args, f.Since.UTC().Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		conditions = append(conditions, "g.created_at < ?")
+		args = append(args, f.Until.UTC().Format(time.RFC3339))
+	}
+	if f.Position != "" {
+		conditions = append(conditions, "p.position = ?")
+		args = append(args, f.Position)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// excludeCondition leaves out the games, identified by the column gameID, flagged with any of flags,
+// SQL expressions of the flags
+func excludeCondition(gameID string, flags []string) string {
+	return "NOT EXISTS (SELECT 1 FROM game_flags gf WHERE gf.game_id = " + gameID + " AND gf.flag IN (" + strings.Join(flags, ", ") + "))"
+}
+
+// ExcludeCondition is the condition Filter.Exclude adds, with the flags written into the SQL for
+// queries that cannot bind arguments, such as the Grafana panels. It is empty when flags is.
+func ExcludeCondition(gameID string, flags []string) string {
+	if len(flags) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(flags))
+	for i, flag := range flags {
+		quoted[i] = "'" + strings.ReplaceAll(flag, "'", "''") + "'"
+	}
+	return excludeCondition(gameID, quoted)
+}
+
+// locale returns the locale of the champion names, which queries join as
+//
+//	LEFT JOIN champion_translations ct ON ct.champion_id = c.champion_id AND ct.locale = ?
+//
+// selecting COALESCE(ct.name, c.name). The placeholders of the joins come before those of where.
+func (f Filter) locale() string {
+	if f.Locale == "" {
+		return config.DefaultLocale
+	}
+	return f.Locale
+}
+
+// withArgs appends extra query arguments to the arguments of a filter without modifying them
+func withArgs(args []any, extra ...any) []any {
+	return append(append([]any{}, args...), extra...)
+}
@@ -0,0 +1,169 @@
+// internal/stats/goals.go
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/db"
+	"opggvisualizer/internal/models"
+)
+
+// goalMetrics maps the metrics a goal can target to their value per game, for a query over
+// participants p, games g and participant_metrics m. Derived metrics are read from participant_metrics,
+// like the stats aggregates, so a goal and the stats it is checked against agree.
+var goalMetrics = map[string]string{
+	"kills":              "p.kills",
+	"deaths":             "p.deaths",
+	"assists":            "p.assists",
+	"kda":                "m.kda",
+	"win":                "(p.result = 'WIN')",
+	"gold":               "p.gold_earned",
+	"damage":             "p.damage_dealt",
+	"vision_score":       "p.vision_score",
+	"vision_per_minute":  "m.vision_per_minute",
+	"cs_per_minute":      "m.cs_per_minute",
+	"kill_participation": "m.kill_participation",
+	"damage_share":       "m.damage_share",
+	"gold_share":         "m.gold_share",
+	"op_score":           "m.op_score",
+}
+
+// GoalMetrics lists the metrics a goal can target
+var GoalMetrics = func() []string {
+	metrics := make([]string, 0, len(goalMetrics))
+	for metric := range goalMetrics {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	return metrics
+}()
+
+// GoalComparisons lists the comparisons a goal can use
+var GoalComparisons = []string{">=", ">", "<=", "<"}
+
+// GoalPeriods lists the periods a goal can cover
+var GoalPeriods = []string{models.PeriodWeek, models.PeriodMonth, models.PeriodAll}
+
+// ValidateGoal reports every problem found in a goal
+func ValidateGoal(goal models.Goal) error {
+	var errs []error
+	if goal.SummonerID == "" {
+		errs = append(errs, fmt.Errorf("summoner is required"))
+	}
+	if _, ok := goalMetrics[goal.Metric]; !ok {
+		errs = append(errs, fmt.Errorf("unknown metric %q, expected one of %s", goal.Metric, strings.Join(GoalMetrics, ", ")))
+	}
+	if !slices.Contains(GoalComparisons, goal.Comparison) {
+		errs = append(errs, fmt.Errorf("unknown comparison %q, expected one of %s", goal.Comparison, strings.Join(GoalComparisons, " ")))
+	}
+	if goal.Share < 0 || goal.Share > 1 {
+		errs = append(errs, fmt.Errorf("share must be between 0 and 100%%"))
+	}
+	if !slices.Contains(GoalPeriods, goal.Period) {
+		errs = append(errs, fmt.Errorf("unknown period %q, expected one of %s", goal.Period, strings.Join(GoalPeriods, ", ")))
+	}
+	if _, err := ParsePosition(goal.Position); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// PeriodStart returns the start of the period containing now, in the time zone of now.
+// The zero time is returned for models.PeriodAll.
+func PeriodStart(period string, now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case models.PeriodWeek:
+		// Weeks start on Monday
+		return midnight.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+	case models.PeriodMonth:
+		return midnight.AddDate(0, 0, 1-now.Day())
+	default:
+		return time.Time{}
+	}
+}
+
+// EvaluateGoal measures the progress of a goal over the games of its period. The games excluded by the
+// stats.exclude setting are not counted.
+func EvaluateGoal(ctx context.Context, cfg *config.Config, database *db.Database, goal models.Goal, now time.Time) (models.GoalProgress, error) {
+	progress := models.GoalProgress{Goal: goal, Description: goal.String(), EvaluatedAt: &now}
+	metric, ok := goalMetrics[goal.Metric]
+	if !ok || !slices.Contains(GoalComparisons, goal.Comparison) {
+		return progress, fmt.Errorf("goal %d: invalid goal %s", goal.ID, goal)
+	}
+
+	filter, err := SummonerFilter(cfg, goal.SummonerID)
+	if err != nil {
+		return progress, err
+	}
+	filter.Since = PeriodStart(goal.Period, now)
+	filter.Position = goal.Position
+	where, args := filter.where()
+
+	// Games without a value for the metric, e.g. creep score before it was recorded, are not counted
+	value := `AVG(` + metric + `)`
+	if goal.Share > 0 {
+		value = `AVG(` + metric + ` ` + goal.Comparison + ` ?)`
+		args = append([]any{goal.Target}, args...)
+	}
+	if err := database.Conn.QueryRowContext(ctx, `SELECT COUNT(`+metric+`), COALESCE(`+value+`, 0)
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	LEFT JOIN participant_metrics m ON m.participant_id = p.id
+	WHERE `+where+`;`, args...).Scan(&progress.Games, &progress.Value); err != nil {
+		return progress, fmt.Errorf("failed to evaluate goal %d: %w", goal.ID, err)
+	}
+
+	if progress.Games > 0 {
+		if goal.Share > 0 {
+			progress.Met = progress.Value >= goal.Share
+		} else {
+			progress.Met = compare(progress.Value, goal.Comparison, goal.Target)
+		}
+	}
+	return progress, nil
+}
+
+// EvaluateGoals evaluates the stored goals of a summoner, or of every summoner if summonerID is empty,
+// stores their progress and returns it in the order the goals were added. Goals are evaluated when
+// their progress is read too, so a weekly goal restarts on Monday before the next games fetch.
+func EvaluateGoals(ctx context.Context, cfg *config.Config, database *db.Database, summonerID string, now time.Time) ([]models.GoalProgress, error) {
+	goals, err := database.ListGoalProgress(ctx, summonerID)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for i, stored := range goals {
+		progress, err := EvaluateGoal(ctx, cfg, database, stored.Goal, now)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := database.UpdateGoalProgress(ctx, progress); err != nil {
+			errs = append(errs, err)
+		}
+		goals[i] = progress
+	}
+	return goals, errors.Join(errs...)
+}
+
+func compare(value float64, comparison string, target float64) bool {
+	switch comparison {
+	case ">=":
+		return value >= target
+	case ">":
+		return value > target
+	case "<=":
+		return value <= target
+	case "<":
+		return value < target
+	}
+	return false
+}
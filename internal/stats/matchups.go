@@ -0,0 +1,111 @@
+// internal/stats/matchups.go
+package stats
+
+import (
+	"context"
+	"fmt"
+
+	"opggvisualizer/internal/db"
+)
+
+// LaneMatchup is the performance of a summoner against their lane opponents in one position
+type LaneMatchup struct {
+	Position   string  `json:"position"`
+	Record     Record  `json:"record"`
+	GoldDiff   float64 `json:"gold_diff"`   // Per game, positive when ahead of the opponent
+	DamageDiff float64 `json:"damage_diff"` // Per game, damage dealt to champions
+	LaneScore  float64 `json:"lane_score"`
+}
+
+// ChampionMatchup is the performance of a summoner on one champion against one lane opponent champion
+type ChampionMatchup struct {
+	ChampionID string  `json:"champion_id"`
+	Champion   string  `json:"champion"`
+	OpponentID string  `json:"opponent_id"`
+	Opponent   string  `json:"opponent"`
+	Record     Record  `json:"record"`
+	GoldDiff   float64 `json:"gold_diff"`
+	DamageDiff float64 `json:"damage_diff"`
+	LaneScore  float64 `json:"lane_score"`
+}
+
+// Matchups compares a summoner with their lane opponents, per position and per champion pair
+type Matchups struct {
+	Lanes     []LaneMatchup     `json:"lanes"`
+	Champions []ChampionMatchup `json:"champions"`
+}
+
+// laneOpponentJoin pairs each participant p with the participant o in the same position on the other team
+const laneOpponentJoin = `FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	JOIN participants o ON o.game_id = p.game_id AND o.position = p.position AND o.team_key <> p.team_key`
+
+// ComputeMatchups aggregates the games matched by filter against the lane opponent.
+// Champion pairs seen in fewer than minGames games are left out.
+func ComputeMatchups(ctx context.Context, database *db.Database, filter Filter, minGames int) (*Matchups, error) {
+	where, args := filter.where()
+	matchups := &Matchups{Lanes: []LaneMatchup{}, Champions: []ChampionMatchup{}}
+
+	rows, err := database.Conn.QueryContext(ctx, `SELECT
+		p.position,
+		COUNT(*),
+		SUM(p.result = 'WIN'),
+		AVG(p.gold_earned - o.gold_earned),
+		AVG(p.damage_dealt - o.damage_dealt),
+		AVG(p.lane_score)
+	`+laneOpponentJoin+`
+	WHERE `+where+`
+	GROUP BY p.position
+	ORDER BY COUNT(*) DESC;`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lane matchups: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var lane LaneMatchup
+		var games, wins int
+		if err := rows.Scan(&lane.Position, &games, &wins, &lane.GoldDiff, &lane.DamageDiff, &lane.LaneScore); err != nil {
+			return nil, err
+		}
+		lane.Record = newRecord(games, wins)
+		matchups.Lanes = append(matchups.Lanes, lane)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pairs, err := database.Conn.QueryContext(ctx, `SELECT
+		p.champion_id,
+		COALESCE(pct.name, pc.name, 'Champion ' || p.champion_id),
+		o.champion_id,
+		COALESCE(oct.name, oc.name, 'Champion ' || o.champion_id),
+		COUNT(*),
+		SUM(p.result = 'WIN'),
+		AVG(p.gold_earned - o.gold_earned),
+		AVG(p.damage_dealt - o.damage_dealt),
+		AVG(p.lane_score)
+	`+laneOpponentJoin+`
+	LEFT JOIN champions pc ON pc.champion_id = p.champion_id
+	LEFT JOIN champions oc ON oc.champion_id = o.champion_id
+	LEFT JOIN champion_translations pct ON pct.champion_id = pc.champion_id AND pct.locale = ?
+	LEFT JOIN champion_translations oct ON oct.champion_id = oc.champion_id AND oct.locale = ?
+	WHERE `+where+`
+	GROUP BY p.champion_id, o.champion_id
+	HAVING COUNT(*) >= ?
+	ORDER BY COUNT(*) DESC, 2, 4;`, withArgs(withArgs([]any{filter.locale(), filter.locale()}, args...), minGames)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query champion matchups: %w", err)
+	}
+	defer pairs.Close()
+	for pairs.Next() {
+		var m ChampionMatchup
+		var games, wins int
+		if err := pairs.Scan(&m.ChampionID, &m.Champion, &m.OpponentID, &m.Opponent, &games, &wins,
+			&m.GoldDiff, &m.DamageDiff, &m.LaneScore); err != nil {
+			return nil, err
+		}
+		m.Record = newRecord(games, wins)
+		matchups.Champions = append(matchups.Champions, m)
+	}
+	return matchups, pairs.Err()
+}
@@ -0,0 +1,183 @@
+// internal/stats/objectives.go
+package stats
+
+import (
+	"context"
+	"fmt"
+
+	"opggvisualizer/internal/db"
+)
+
+// objective names a team objective and its columns in the teams table
+type objective struct {
+	name  string
+	first string
+	kills string // Empty when the count is not an objective, e.g. champion kills for first blood
+}
+
+// objectives in the order they are reported, named like the firsts of a scoreboard
+var objectives = []objective{
+	{"blood", "champion_first", ""},
+	{"tower", "tower_first", "tower_kill"},
+	{"dragon", "dragon_first", "dragon_kill"},
+	{"herald", "rift_herald_first", "rift_herald_kill"},
+	{"grubs", "horde_first", "horde_kill"},
+	{"baron", "baron_first", "baron_kill"},
+	{"inhibitor", "inhibitor_first", "inhibitor_kill"},
+}
+
+// FirstObjective is the record of a summoner when their team took an objective first, and when the enemy team did.
+// Games where neither team took the objective are in neither record.
+type FirstObjective struct {
+	Objective string `json:"objective"`
+	Taken     Record `json:"taken"`
+	Conceded  Record `json:"conceded"`
+}
+
+// ObjectiveCount is the record of a summoner in the games their team took an objective a number of times
+type ObjectiveCount struct {
+	Count  int    `json:"count"`
+	Record Record `json:"record"`
+}
+
+// ObjectiveDistribution is how many times the team of a summoner took an objective per game
+type ObjectiveDistribution struct {
+	Objective string           `json:"objective"`
+	Taken     float64          `json:"taken"`    // Average per game
+	Conceded  float64          `json:"conceded"` // Average taken by the enemy team per game
+	Counts    []ObjectiveCount `json:"counts"`
+}
+
+// SideGold is the record and team gold difference of a summoner on one side of the map
+type SideGold struct {
+	Side     string  `json:"side"` // BLUE or RED
+	Record   Record  `json:"record"`
+	GoldDiff float64 `json:"gold_diff"` // Per game, team gold minus enemy team gold
+	WinDiff  float64 `json:"win_gold_diff"`
+	LossDiff float64 `json:"loss_gold_diff"`
+}
+
+// Objectives relates the objectives taken by the team of a summoner to their results
+type Objectives struct {
+	Overall Record                  `json:"overall"`
+	Firsts  []FirstObjective        `json:"firsts"`
+	Counts  []ObjectiveDistribution `json:"counts"`
+	Sides   []SideGold              `json:"sides"`
+}
+
+// teamJoin pairs each participant p with their team t and the enemy team e
+const teamJoin = `FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	JOIN teams t ON t.game_id = p.game_id AND t.key = p.team_key
+	JOIN teams e ON e.game_id = p.game_id AND e.key <> p.team_key`
+
+// ComputeObjectives aggregates the team objectives of the games matched by filter
+func ComputeObjectives(ctx context.Context, database *db.Database, filter Filter) (*Objectives, error) {
+	where, args := filter.where()
+	result := &Objectives{Firsts: []FirstObjective{}, Counts: []ObjectiveDistribution{}, Sides: []SideGold{}}
+
+	var games, wins int
+	if err := database.Conn.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(p.result = 'WIN'), 0)
+	`+teamJoin+`
+	WHERE `+where+`;`, args...).Scan(&games, &wins); err != nil {
+		return nil, fmt.Errorf("failed to query overall record: %w", err)
+	}
+	result.Overall = newRecord(games, wins)
+
+	for _, o := range objectives {
+		var taken, takenWins, conceded, concededWins int
+		if err := database.Conn.QueryRowContext(ctx, `SELECT
+			COALESCE(SUM(t.`+o.first+`), 0),
+			COALESCE(SUM(t.`+o.first+` AND p.result = 'WIN'), 0),
+			COALESCE(SUM(e.`+o.first+`), 0),
+			COALESCE(SUM(e.`+o.first+` AND p.result = 'WIN'), 0)
+		`+teamJoin+`
+		WHERE `+where+`;`, args...).Scan(&taken, &takenWins, &conceded, &concededWins); err != nil {
+			return nil, fmt.Errorf("failed to query first %s: %w", o.name, err)
+		}
+		result.Firsts = append(result.Firsts, FirstObjective{
+			Objective: o.name,
+			Taken:     newRecord(taken, takenWins),
+			Conceded:  newRecord(conceded, concededWins),
+		})
+
+		if o.kills == "" {
+			continue
+		}
+		distribution, err := objectiveDistribution(ctx, database, where, args, o)
+		if err != nil {
+			return nil, err
+		}
+		result.Counts = append(result.Counts, *distribution)
+	}
+
+	var err error
+	if result.Sides, err = sideGold(ctx, database, where, args); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func objectiveDistribution(ctx context.Context, database *db.Database, where string, args []any, o objective) (*ObjectiveDistribution, error) {
+	distribution := &ObjectiveDistribution{Objective: o.name, Counts: []ObjectiveCount{}}
+	if err := database.Conn.QueryRowContext(ctx, `SELECT
+		COALESCE(AVG(t.`+o.kills+`), 0),
+		COALESCE(AVG(e.`+o.kills+`), 0)
+	`+teamJoin+`
+	WHERE `+where+`;`, args...).Scan(&distribution.Taken, &distribution.Conceded); err != nil {
+		return nil, fmt.Errorf("failed to query %s average: %w", o.name, err)
+	}
+
+	rows, err := database.Conn.QueryContext(ctx, `SELECT
+		t.`+o.kills+`,
+		COUNT(*),
+		SUM(p.result = 'WIN')
+	`+teamJoin+`
+	WHERE `+where+`
+	GROUP BY t.`+o.kills+`
+	ORDER BY t.`+o.kills+`;`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s counts: %w", o.name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c ObjectiveCount
+		var games, wins int
+		if err := rows.Scan(&c.Count, &games, &wins); err != nil {
+			return nil, err
+		}
+		c.Record = newRecord(games, wins)
+		distribution.Counts = append(distribution.Counts, c)
+	}
+	return distribution, rows.Err()
+}
+
+func sideGold(ctx context.Context, database *db.Database, where string, args []any) ([]SideGold, error) {
+	rows, err := database.Conn.QueryContext(ctx, `SELECT
+		t.key,
+		COUNT(*),
+		SUM(p.result = 'WIN'),
+		AVG(t.gold_earned - e.gold_earned),
+		COALESCE(AVG(CASE WHEN p.result = 'WIN' THEN t.gold_earned - e.gold_earned END), 0),
+		COALESCE(AVG(CASE WHEN p.result <> 'WIN' THEN t.gold_earned - e.gold_earned END), 0)
+	`+teamJoin+`
+	WHERE `+where+`
+	GROUP BY t.key
+	ORDER BY t.key;`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query side gold: %w", err)
+	}
+	defer rows.Close()
+
+	sides := []SideGold{}
+	for rows.Next() {
+		var s SideGold
+		var games, wins int
+		if err := rows.Scan(&s.Side, &games, &wins, &s.GoldDiff, &s.WinDiff, &s.LossDiff); err != nil {
+			return nil, err
+		}
+		s.Record = newRecord(games, wins)
+		sides = append(sides, s)
+	}
+	return sides, rows.Err()
+}
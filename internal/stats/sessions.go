@@ -0,0 +1,237 @@
+// internal/stats/sessions.go
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"opggvisualizer/internal/db"
+)
+
+// DefaultSessionGap is the longest break between two games of the same session
+const DefaultSessionGap = time.Hour
+
+// Session is a run of games played with breaks shorter than the session gap
+type Session struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"` // End of the last game
+	Record  Record    `json:"record"`
+	Results string    `json:"results"` // One letter per game in order, W or L
+}
+
+// Streak is a run of games with the same result
+type Streak struct {
+	Result string    `json:"result"` // WIN or LOSE
+	Games  int       `json:"games"`
+	Since  time.Time `json:"since"` // Start of the first game
+}
+
+// GroupRecord is the record of the games in one group, e.g. one hour of the day
+type GroupRecord struct {
+	Group  string  `json:"group"`
+	Record Record  `json:"record"`
+	KDA    float64 `json:"kda"` // Per game average of participant_metrics
+}
+
+// Sessions groups the games of a summoner into play sessions and relates their results to when they were played
+type Sessions struct {
+	Sessions          []Session     `json:"sessions"` // Newest first
+	CurrentStreak     Streak        `json:"current_streak"`
+	LongestWinStreak  Streak        `json:"longest_win_streak"`
+	LongestLossStreak Streak        `json:"longest_loss_streak"`
+	BySessionGame     []GroupRecord `json:"by_session_game"` // Position of the game in its session: 1, 2, 3, 4 and 5+
+	AfterLosses       []GroupRecord `json:"after_losses"`    // Losses in a row earlier in the same session: 0, 1, 2 and 3+
+	ByHour            []GroupRecord `json:"by_hour"`         // Local hour the game started, hours without games are left out
+	ByWeekday         []GroupRecord `json:"by_weekday"`      // Monday first, days without games are left out
+}
+
+// Groups past the last one are counted in the last, e.g. the sixth game of a session is in "5+"
+const (
+	sessionGameGroups = 5
+	afterLossGroups   = 4
+)
+
+type sessionGame struct {
+	start time.Time
+	end   time.Time
+	win   bool
+	kda   sql.NullFloat64 // NULL for games without participant_metrics
+}
+
+// ComputeSessions groups the games matched by filter into sessions. A game starting less than gap after
+// the end of the previous game belongs to the same session. Times of day are in loc.
+func ComputeSessions(ctx context.Context, database *db.Database, filter Filter, gap time.Duration, loc *time.Location) (*Sessions, error) {
+	where, args := filter.where()
+	rows, err := database.Conn.QueryContext(ctx, `SELECT g.created_at, g.game_length, p.result = 'WIN', m.kda
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	LEFT JOIN participant_metrics m ON m.participant_id = p.id
+	WHERE `+where+`
+	ORDER BY g.created_at;`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []sessionGame
+	for rows.Next() {
+		var g sessionGame
+		var createdAt string
+		var length int
+		if err := rows.Scan(&createdAt, &length, &g.win, &g.kda); err != nil {
+			return nil, err
+		}
+		if g.start, err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at %q: %w", createdAt, err)
+		}
+		g.end = g.start.Add(time.Duration(length) * time.Second)
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &Sessions{Sessions: []Session{}}
+	bySessionGame := newGroups()
+	afterLosses := newGroups()
+	byHour := newGroups()
+	byWeekday := newGroups()
+
+	var current []sessionGame
+	flush := func() {
+		if len(current) > 0 {
+			result.Sessions = append(result.Sessions, newSession(current))
+		}
+		current = nil
+	}
+	var streak Streak
+	for i, g := range games {
+		if i > 0 && g.start.Sub(games[i-1].end) >= gap {
+			flush()
+		}
+
+		lossesBefore := 0
+		for j := len(current) - 1; j >= 0 && !current[j].win; j-- {
+			lossesBefore++
+		}
+		current = append(current, g)
+
+		bySessionGame.add(cappedGroup(len(current), sessionGameGroups), g)
+		afterLosses.add(cappedGroup(lossesBefore, afterLossGroups-1), g)
+		local := g.start.In(loc)
+		byHour.add(fmt.Sprintf("%02d:00", local.Hour()), g)
+		byWeekday.add(local.Weekday().String(), g)
+
+		resultName := "LOSE"
+		if g.win {
+			resultName = "WIN"
+		}
+		if streak.Result != resultName {
+			streak = Streak{Result: resultName, Since: g.start}
+		}
+		streak.Games++
+		if g.win && streak.Games > result.LongestWinStreak.Games {
+			result.LongestWinStreak = streak
+		}
+		if !g.win && streak.Games > result.LongestLossStreak.Games {
+			result.LongestLossStreak = streak
+		}
+	}
+	flush()
+	result.CurrentStreak = streak
+
+	// Newest session first
+	for i, j := 0, len(result.Sessions)-1; i < j; i, j = i+1, j-1 {
+		result.Sessions[i], result.Sessions[j] = result.Sessions[j], result.Sessions[i]
+	}
+
+	var sessionGameOrder, afterLossOrder, hourOrder []string
+	for i := 1; i <= sessionGameGroups; i++ {
+		sessionGameOrder = append(sessionGameOrder, cappedGroup(i, sessionGameGroups))
+	}
+	for i := 0; i < afterLossGroups; i++ {
+		afterLossOrder = append(afterLossOrder, cappedGroup(i, afterLossGroups-1))
+	}
+	for h := 0; h < 24; h++ {
+		hourOrder = append(hourOrder, fmt.Sprintf("%02d:00", h))
+	}
+	weekdayOrder := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+	result.BySessionGame = bySessionGame.records(sessionGameOrder)
+	result.AfterLosses = afterLosses.records(afterLossOrder)
+	result.ByHour = byHour.records(hourOrder)
+	result.ByWeekday = byWeekday.records(weekdayOrder)
+	return result, nil
+}
+
+func newSession(games []sessionGame) Session {
+	s := Session{Start: games[0].start, End: games[len(games)-1].end}
+	wins := 0
+	for _, g := range games {
+		if g.win {
+			wins++
+			s.Results += "W"
+		} else {
+			s.Results += "L"
+		}
+	}
+	s.Record = newRecord(len(games), wins)
+	return s
+}
+
+// cappedGroup names the group of n, counting values of limit and above as "limit+"
+func cappedGroup(n, limit int) string {
+	if n >= limit {
+		return strconv.Itoa(limit) + "+"
+	}
+	return strconv.Itoa(n)
+}
+
+type groupSum struct {
+	games, wins int
+	kda         float64 // Sum over the kdaGames games with metrics
+	kdaGames    int
+}
+
+// groups sums the games of each group
+type groups map[string]*groupSum
+
+func newGroups() groups {
+	return make(groups)
+}
+
+func (gs groups) add(group string, g sessionGame) {
+	sum, ok := gs[group]
+	if !ok {
+		sum = &groupSum{}
+		gs[group] = sum
+	}
+	sum.games++
+	if g.win {
+		sum.wins++
+	}
+	if g.kda.Valid {
+		sum.kda += g.kda.Float64
+		sum.kdaGames++
+	}
+}
+
+// records returns the records of the groups in order, leaving out groups without games
+func (gs groups) records(order []string) []GroupRecord {
+	records := []GroupRecord{}
+	for _, group := range order {
+		sum, ok := gs[group]
+		if !ok {
+			continue
+		}
+		records = append(records, GroupRecord{
+			Group:  group,
+			Record: newRecord(sum.games, sum.wins),
+			KDA:    sum.kda / float64(max(sum.kdaGames, 1)),
+		})
+	}
+	return records
+}
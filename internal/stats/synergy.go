@@ -0,0 +1,157 @@
+// internal/stats/synergy.go
+package stats
+
+import (
+	"context"
+	"fmt"
+
+	"opggvisualizer/internal/db"
+)
+
+// Record is a number of games and how many of them were won
+type Record struct {
+	Games   int     `json:"games"`
+	Wins    int     `json:"wins"`
+	WinRate float64 `json:"win_rate"` // 0 to 1
+}
+
+func newRecord(games, wins int) Record {
+	r := Record{Games: games, Wins: wins}
+	if games > 0 {
+		r.WinRate = float64(wins) / float64(games)
+	}
+	return r
+}
+
+// TeammateStats is the performance of a summoner in the games played on the same team as a teammate
+type TeammateStats struct {
+	Teammate    string  `json:"teammate"` // Summoner name
+	TeammateID  string  `json:"teammate_id,omitempty"`
+	Tracked     bool    `json:"tracked"` // The teammate is a configured summoner
+	Record      Record  `json:"record"`
+	KDA         float64 `json:"kda"`
+	OPScoreRank float64 `json:"op_score_rank"`
+}
+
+// ChampionPresence is the record of a summoner in the games a champion was played by an ally or an enemy
+type ChampionPresence struct {
+	ChampionID string `json:"champion_id"`
+	Champion   string `json:"champion"`
+	Record     Record `json:"record"`
+}
+
+// Synergy relates the results of a summoner to who and what they played with and against
+type Synergy struct {
+	Overall   Record             `json:"overall"`
+	Teammates []TeammateStats    `json:"teammates"`
+	Allies    []ChampionPresence `json:"allies"`  // Champions on the summoner's team
+	Enemies   []ChampionPresence `json:"enemies"` // Champions on the enemy team
+}
+
+// SynergyOptions controls which teammates and champions are reported
+type SynergyOptions struct {
+	MinGames int      // Teammates and champions seen in fewer games are left out
+	Tracked  []string // op.gg ids of the configured summoners, reported as tracked teammates
+}
+
+// ComputeSynergy aggregates the games matched by filter per teammate and per ally and enemy champion
+func ComputeSynergy(ctx context.Context, database *db.Database, filter Filter, opts SynergyOptions) (*Synergy, error) {
+	where, args := filter.where()
+	synergy := &Synergy{}
+
+	var games, wins int
+	if err := database.Conn.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(p.result = 'WIN'), 0)
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	WHERE `+where+`;`, args...).Scan(&games, &wins); err != nil {
+		return nil, fmt.Errorf("failed to query overall record: %w", err)
+	}
+	synergy.Overall = newRecord(games, wins)
+
+	var err error
+	if synergy.Teammates, err = teammates(ctx, database, where, args, opts); err != nil {
+		return nil, err
+	}
+	if synergy.Allies, err = championPresence(ctx, database, where, args, filter.locale(), "=", opts.MinGames); err != nil {
+		return nil, err
+	}
+	if synergy.Enemies, err = championPresence(ctx, database, where, args, filter.locale(), "<>", opts.MinGames); err != nil {
+		return nil, err
+	}
+	return synergy, nil
+}
+
+func teammates(ctx context.Context, database *db.Database, where string, args []any, opts SynergyOptions) ([]TeammateStats, error) {
+	// Teammates are told apart by op.gg id, or by name for games stored before ids were recorded
+	rows, err := database.Conn.QueryContext(ctx, `SELECT
+		MAX(t.summoner_name),
+		COALESCE(t.summoner_id, ''),
+		COUNT(*),
+		SUM(p.result = 'WIN'),
+		COALESCE(AVG(m.kda), 0),
+		AVG(p.op_score_rank)
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	LEFT JOIN participant_metrics m ON m.participant_id = p.id
+	JOIN participants t ON t.game_id = p.game_id AND t.team_key = p.team_key AND t.id <> p.id
+	WHERE `+where+`
+	GROUP BY COALESCE(t.summoner_id, t.summoner_name)
+	HAVING COUNT(*) >= ?
+	ORDER BY COUNT(*) DESC, 1;`, withArgs(args, opts.MinGames)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teammates: %w", err)
+	}
+	defer rows.Close()
+
+	tracked := make(map[string]bool)
+	for _, id := range opts.Tracked {
+		tracked[id] = true
+	}
+
+	result := []TeammateStats{}
+	for rows.Next() {
+		var t TeammateStats
+		var games, wins int
+		if err := rows.Scan(&t.Teammate, &t.TeammateID, &games, &wins, &t.KDA, &t.OPScoreRank); err != nil {
+			return nil, err
+		}
+		t.Record = newRecord(games, wins)
+		t.Tracked = tracked[t.TeammateID]
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+// championPresence aggregates the champions of the other participants. comparison is "=" for allies and "<>" for enemies.
+func championPresence(ctx context.Context, database *db.Database, where string, args []any, locale, comparison string, minGames int) ([]ChampionPresence, error) {
+	rows, err := database.Conn.QueryContext(ctx, `SELECT
+		o.champion_id,
+		COALESCE(ct.name, c.name, 'Champion ' || o.champion_id),
+		COUNT(*),
+		SUM(p.result = 'WIN')
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	JOIN participants o ON o.game_id = p.game_id AND o.id <> p.id AND o.team_key `+comparison+` p.team_key
+	LEFT JOIN champions c ON c.champion_id = o.champion_id
+	LEFT JOIN champion_translations ct ON ct.champion_id = c.champion_id AND ct.locale = ?
+	WHERE `+where+`
+	GROUP BY o.champion_id
+	HAVING COUNT(*) >= ?
+	ORDER BY COUNT(*) DESC, 2;`, withArgs(withArgs([]any{locale}, args...), minGames)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query champion presence: %w", err)
+	}
+	defer rows.Close()
+
+	result := []ChampionPresence{}
+	for rows.Next() {
+		var c ChampionPresence
+		var games, wins int
+		if err := rows.Scan(&c.ChampionID, &c.Champion, &games, &wins); err != nil {
+			return nil, err
+		}
+		c.Record = newRecord(games, wins)
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,137 @@
+// internal/stats/tempo.go
+package stats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"opggvisualizer/internal/db"
+)
+
+// LengthBucket is a range of game lengths. A zero To has no upper bound.
+type LengthBucket struct {
+	Name string
+	From time.Duration
+	To   time.Duration
+}
+
+// LengthBuckets split games into early, mid and late games
+var LengthBuckets = []LengthBucket{
+	{Name: "early", From: 0, To: 25 * time.Minute},
+	{Name: "mid", From: 25 * time.Minute, To: 35 * time.Minute},
+	{Name: "late", From: 35 * time.Minute},
+}
+
+// PerMinute is a participant's output divided by the game time it was achieved in, averaged over
+// the games from participant_metrics
+type PerMinute struct {
+	Gold   float64 `json:"gold"`
+	Damage float64 `json:"damage"` // Damage dealt to champions
+	CS     float64 `json:"cs"`     // Only games stored with creep score are counted
+	Vision float64 `json:"vision"`
+}
+
+// perMinuteColumns selects the fields of PerMinute in order, for a query joining participant_metrics m
+const perMinuteColumns = `COALESCE(AVG(m.gold_per_minute), 0),
+		COALESCE(AVG(m.damage_per_minute), 0),
+		COALESCE(AVG(m.cs_per_minute), 0),
+		COALESCE(AVG(m.vision_per_minute), 0)`
+
+// TempoStats is the performance of a summoner in the games of one length bucket
+type TempoStats struct {
+	Bucket     string    `json:"bucket"`
+	FromMinute int       `json:"from_minute"`
+	ToMinute   int       `json:"to_minute,omitempty"` // Left out for the last bucket
+	Record     Record    `json:"record"`
+	GameLength float64   `json:"game_length"` // Average, in seconds
+	PerMinute  PerMinute `json:"per_minute"`
+}
+
+// Tempo compares a summoner's games by length
+type Tempo struct {
+	Overall TempoStats   `json:"overall"`
+	Buckets []TempoStats `json:"buckets"` // In the order of LengthBuckets, empty buckets included
+}
+
+// ComputeTempo aggregates the games matched by filter per LengthBuckets bucket
+func ComputeTempo(ctx context.Context, database *db.Database, filter Filter) (*Tempo, error) {
+	where, args := filter.where()
+	tempo := &Tempo{Overall: TempoStats{Bucket: "all"}, Buckets: []TempoStats{}}
+	for _, b := range LengthBuckets {
+		tempo.Buckets = append(tempo.Buckets, TempoStats{
+			Bucket:     b.Name,
+			FromMinute: int(b.From.Minutes()),
+			ToMinute:   int(b.To.Minutes()),
+		})
+	}
+
+	if err := scanTempo(database.Conn.QueryRowContext(ctx, `SELECT
+		COUNT(*),
+		COALESCE(SUM(p.result = 'WIN'), 0),
+		COALESCE(AVG(g.game_length), 0),
+		`+perMinuteColumns+`
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	LEFT JOIN participant_metrics m ON m.participant_id = p.id
+	WHERE `+where+`;`, args...), &tempo.Overall); err != nil {
+		return nil, fmt.Errorf("failed to query overall tempo: %w", err)
+	}
+
+	bucket, bucketArgs := bucketCase()
+	rows, err := database.Conn.QueryContext(ctx, `SELECT
+		`+bucket+`,
+		COUNT(*),
+		SUM(p.result = 'WIN'),
+		AVG(g.game_length),
+		`+perMinuteColumns+`
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	LEFT JOIN participant_metrics m ON m.participant_id = p.id
+	WHERE `+where+`
+	GROUP BY 1;`, append(bucketArgs, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tempo: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var i int
+		var s TempoStats
+		if err := scanTempo(rows, &s, &i); err != nil {
+			return nil, err
+		}
+		s.Bucket, s.FromMinute, s.ToMinute = tempo.Buckets[i].Bucket, tempo.Buckets[i].FromMinute, tempo.Buckets[i].ToMinute
+		tempo.Buckets[i] = s
+	}
+	return tempo, rows.Err()
+}
+
+// bucketCase returns an expression giving the index in LengthBuckets of the game g
+func bucketCase() (string, []any) {
+	var sql strings.Builder
+	var args []any
+	sql.WriteString("CASE")
+	for i, b := range LengthBuckets {
+		if b.To == 0 {
+			fmt.Fprintf(&sql, " ELSE %d", i)
+			break
+		}
+		fmt.Fprintf(&sql, " WHEN g.game_length < ? THEN %d", i)
+		args = append(args, int(b.To.Seconds()))
+	}
+	sql.WriteString(" END")
+	return sql.String(), args
+}
+
+// scanTempo scans a row of tempo columns, after the leading columns in dest
+func scanTempo(row interface{ Scan(...any) error }, s *TempoStats, dest ...any) error {
+	var games, wins int
+	dest = append(dest, &games, &wins, &s.GameLength,
+		&s.PerMinute.Gold, &s.PerMinute.Damage, &s.PerMinute.CS, &s.PerMinute.Vision)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	s.Record = newRecord(games, wins)
+	return nil
+}
@@ -0,0 +1,191 @@
+// internal/stats/weekly.go
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"opggvisualizer/internal/db"
+	"opggvisualizer/internal/models"
+)
+
+// WeekSummary aggregates the games of one week
+type WeekSummary struct {
+	Record            Record  `json:"record"`
+	KDA               float64 `json:"kda"`
+	OPScore           float64 `json:"op_score"`      // Average of participant_metrics.op_score, higher is better
+	CSPerMinute       float64 `json:"cs_per_minute"` // Only games stored with creep score are counted
+	VisionPerMinute   float64 `json:"vision_per_minute"`
+	KillParticipation float64 `json:"kill_participation"` // 0 to 1
+}
+
+// WeekDelta is the change of a WeekSummary from the previous week
+type WeekDelta struct {
+	Games             int     `json:"games"`
+	WinRate           float64 `json:"win_rate"`
+	KDA               float64 `json:"kda"`
+	OPScore           float64 `json:"op_score"`
+	CSPerMinute       float64 `json:"cs_per_minute"`
+	VisionPerMinute   float64 `json:"vision_per_minute"`
+	KillParticipation float64 `json:"kill_participation"`
+}
+
+// NotableGame is a game that stood out during the week
+type NotableGame struct {
+	Title string             `json:"title"` // e.g. "Highest OP score"
+	Value float64            `json:"value"` // The value the game stood out by
+	Game  models.GameSummary `json:"game"`
+}
+
+// WeeklyReport summarizes the games of a summoner in one week and compares them with the week before
+type WeeklyReport struct {
+	SummonerID     string          `json:"summoner_id"`
+	Summoner       string          `json:"summoner"` // Configured name, or the id
+	Start          time.Time       `json:"start"`    // Monday 00:00
+	End            time.Time       `json:"end"`      // Start of the next week
+	Week           WeekSummary     `json:"week"`
+	PreviousWeek   WeekSummary     `json:"previous_week"`
+	Delta          WeekDelta       `json:"delta"`
+	BestChampions  []ChampionStats `json:"best_champions"`  // Highest win rate first
+	WorstChampions []ChampionStats `json:"worst_champions"` // Lowest win rate first, never also in BestChampions
+	Notable        []NotableGame   `json:"notable"`
+}
+
+// reportChampions is the most champions listed as best or as worst
+const reportChampions = 3
+
+// notableGames lists the values games of the week are picked by, for a query over participants p,
+// games g and participant_metrics m
+var notableGames = []struct {
+	title string
+	value string
+}{
+	{"Highest OP score", "m.op_score"},
+	{"Most deaths", "p.deaths"},
+}
+
+// LastWeek returns the start of the last full week before now, in the time zone of now
+func LastWeek(now time.Time) time.Time {
+	return PeriodStart(models.PeriodWeek, now).AddDate(0, 0, -7)
+}
+
+// ParseWeek returns the start of the week containing a date such as "2024-11-04", or of the last full
+// week before now if value is empty
+func ParseWeek(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return LastWeek(now), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid week %q, expected a date such as 2024-11-04", value)
+	}
+	return PeriodStart(models.PeriodWeek, day), nil
+}
+
+// ComputeWeeklyReport summarizes the week starting at start for the summoner of filter. The Since
+// and Until fields of filter are replaced.
+func ComputeWeeklyReport(ctx context.Context, database *db.Database, filter Filter, start time.Time) (*WeeklyReport, error) {
+	start = PeriodStart(models.PeriodWeek, start)
+	report := &WeeklyReport{
+		SummonerID:     filter.SummonerID,
+		Summoner:       filter.SummonerName,
+		Start:          start,
+		End:            start.AddDate(0, 0, 7),
+		BestChampions:  []ChampionStats{},
+		WorstChampions: []ChampionStats{},
+		Notable:        []NotableGame{},
+	}
+	if report.Summoner == "" {
+		report.Summoner = filter.SummonerID
+	}
+
+	previous := filter
+	previous.Since, previous.Until = start.AddDate(0, 0, -7), start
+	if err := weekSummary(ctx, database, previous, &report.PreviousWeek); err != nil {
+		return nil, err
+	}
+	filter.Since, filter.Until = report.Start, report.End
+	if err := weekSummary(ctx, database, filter, &report.Week); err != nil {
+		return nil, err
+	}
+	week, prev := report.Week, report.PreviousWeek
+	report.Delta = WeekDelta{
+		Games:             week.Record.Games - prev.Record.Games,
+		WinRate:           week.Record.WinRate - prev.Record.WinRate,
+		KDA:               week.KDA - prev.KDA,
+		OPScore:           week.OPScore - prev.OPScore,
+		CSPerMinute:       week.CSPerMinute - prev.CSPerMinute,
+		VisionPerMinute:   week.VisionPerMinute - prev.VisionPerMinute,
+		KillParticipation: week.KillParticipation - prev.KillParticipation,
+	}
+
+	champions, err := Champions(ctx, database, filter)
+	if err != nil {
+		return nil, err
+	}
+	// Win rate first, KDA breaks ties
+	sort.SliceStable(champions, func(i, j int) bool {
+		if champions[i].WinRate != champions[j].WinRate {
+			return champions[i].WinRate > champions[j].WinRate
+		}
+		return champions[i].KDA > champions[j].KDA
+	})
+	best := min(reportChampions, (len(champions)+1)/2)
+	report.BestChampions = append(report.BestChampions, champions[:best]...)
+	for i := len(champions) - 1; i >= best && len(report.WorstChampions) < reportChampions; i-- {
+		report.WorstChampions = append(report.WorstChampions, champions[i])
+	}
+
+	where, args := filter.where()
+	for _, notable := range notableGames {
+		var gameID string
+		var value float64
+		err := database.Conn.QueryRowContext(ctx, `SELECT p.game_id, `+notable.value+`
+		FROM participants p
+		JOIN games g ON g.game_id = p.game_id
+		LEFT JOIN participant_metrics m ON m.participant_id = p.id
+		WHERE `+where+` AND `+notable.value+` IS NOT NULL
+		ORDER BY 2 DESC, g.created_at DESC
+		LIMIT 1;`, args...).Scan(&gameID, &value)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", notable.title, err)
+		}
+		game, err := database.GetGameSummary(ctx, gameID, filter.SummonerID, report.Summoner, filter.locale())
+		if err != nil {
+			return nil, err
+		}
+		if game != nil {
+			report.Notable = append(report.Notable, NotableGame{Title: notable.title, Value: value, Game: *game})
+		}
+	}
+	return report, nil
+}
+
+// weekSummary aggregates the games matched by filter into s
+func weekSummary(ctx context.Context, database *db.Database, filter Filter, s *WeekSummary) error {
+	where, args := filter.where()
+	var games, wins int
+	if err := database.Conn.QueryRowContext(ctx, `SELECT
+		COUNT(*),
+		COALESCE(SUM(p.result = 'WIN'), 0),
+		COALESCE(AVG(m.kda), 0),
+		COALESCE(AVG(m.op_score), 0),
+		COALESCE(AVG(m.cs_per_minute), 0),
+		COALESCE(AVG(m.vision_per_minute), 0),
+		COALESCE(AVG(m.kill_participation), 0)
+	FROM participants p
+	JOIN games g ON g.game_id = p.game_id
+	LEFT JOIN participant_metrics m ON m.participant_id = p.id
+	WHERE `+where+`;`, args...).Scan(&games, &wins, &s.KDA, &s.OPScore, &s.CSPerMinute, &s.VisionPerMinute, &s.KillParticipation); err != nil {
+		return fmt.Errorf("failed to query week summary: %w", err)
+	}
+	s.Record = newRecord(games, wins)
+	return nil
+}
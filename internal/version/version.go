@@ -0,0 +1,22 @@
+// internal/version/version.go
+package version
+
+import "runtime/debug"
+
+// Version is set at build time with -ldflags "-X opggvisualizer/internal/version.Version=<version>"
+var Version = ""
+
+// Get returns the build version, falling back to the VCS revision recorded by the Go toolchain
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && len(setting.Value) >= 7 {
+				return setting.Value[:7]
+			}
+		}
+	}
+	return "dev"
+}
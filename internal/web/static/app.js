@@ -0,0 +1,233 @@
+// Web UI of opggvisualizer. Every view is rendered from the read API of the server that serves this page.
+"use strict";
+
+const state = {
+  summoner: localStorage.getItem("summoner") || "",
+  token: localStorage.getItem("token") || "",
+  names: {}, // Summoner names seen in games, by summoner id
+};
+
+const view = document.getElementById("view");
+
+async function api(path) {
+  const headers = state.token ? { Authorization: "Bearer " + state.token } : {};
+  // Champions are named in the language of the browser where a translation is stored
+  const url = new URL(path, location.href);
+  url.searchParams.set("lang", navigator.language);
+  const resp = await fetch(url, { headers });
+  if (resp.status === 401) {
+    // api.auth.protect_reads is set, the token is kept in this browser
+    document.getElementById("token-form").hidden = false;
+    throw new Error("An API token is required");
+  }
+  if (!resp.ok) {
+    throw new Error((await resp.text()) || resp.statusText);
+  }
+  return resp.json();
+}
+
+function esc(value) {
+  return String(value ?? "").replace(/[&<>"']/g, (c) => ({ "&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;" })[c]);
+}
+
+function percent(v) {
+  return Math.round(v * 100) + "%";
+}
+
+function fixed(v, digits = 2) {
+  return Number(v).toFixed(digits);
+}
+
+function gameLength(seconds) {
+  return Math.floor(seconds / 60) + ":" + String(seconds % 60).padStart(2, "0");
+}
+
+function date(value) {
+  return new Date(value).toLocaleString(undefined, { dateStyle: "medium", timeStyle: "short" });
+}
+
+// Icons are cropped from the sprite sheets synced by "assets sync" and left out when missing
+function championIcon(champion) {
+  return `<img class="icon" src="sprites/champion/${encodeURIComponent(champion.id)}.png" alt="" onerror="this.remove()">`;
+}
+
+function flags(list) {
+  return (list || []).map((f) => `<span class="flag">${esc(f)}</span>`).join("");
+}
+
+function summonerQuery() {
+  return state.summoner ? "summoner=" + encodeURIComponent(state.summoner) : "";
+}
+
+async function renderGames() {
+  const games = await api("games?limit=50&" + summonerQuery());
+  if (games.length > 0) {
+    state.names[state.summoner] = games[0].summoner;
+  }
+  if (games.length === 0) {
+    view.innerHTML = `<h1>Match history</h1><p class="muted">No games stored yet, run: games fetch</p>`;
+    return;
+  }
+  const rows = games.map((g) => {
+    const win = g.result === "WIN";
+    return `<tr class="link ${win ? "win" : "loss"}" data-game="${esc(g.game_id)}">
+      <td class="${win ? "win-text" : "loss-text"}">${win ? "Win" : "Loss"}</td>
+      <td>${championIcon(g.champion)}${esc(g.champion.name || g.champion.id)}</td>
+      <td>${esc(g.position)}</td>
+      <td class="number">${g.kills} / ${g.deaths} / ${g.assists}</td>
+      <td class="number">${gameLength(g.game_length)}</td>
+      <td>${esc(g.patch)}</td>
+      <td>${date(g.created_at)}</td>
+      <td>${flags(g.flags)}</td>
+    </tr>`;
+  });
+  view.innerHTML = `<h1>Match history: ${esc(games[0].summoner)}</h1>
+    <table>
+      <tr><th>Result</th><th>Champion</th><th>Position</th><th class="number">K / D / A</th><th class="number">Length</th><th>Patch</th><th>Played</th><th>Flags</th></tr>
+      ${rows.join("")}
+    </table>`;
+  view.querySelectorAll("tr[data-game]").forEach((tr) => {
+    tr.addEventListener("click", () => (location.hash = "#/games/" + encodeURIComponent(tr.dataset.game)));
+  });
+}
+
+function renderTeam(team, me) {
+  const objectives = [
+    `${team.towers} towers`,
+    `${team.dragons} dragons`,
+    `${team.barons} barons`,
+    `${team.heralds} heralds`,
+    `${team.hordes} grubs`,
+    `${team.inhibitors} inhibitors`,
+  ].join(", ");
+  const players = team.players.map((p) => `<tr class="${p.summoner_name === me ? "me" : ""}">
+      <td>${championIcon(p.champion)}${esc(p.champion.name || p.champion.id)}</td>
+      <td>${esc(p.summoner_name)}</td>
+      <td>${esc(p.position)}</td>
+      <td class="number">${p.kills} / ${p.deaths} / ${p.assists}</td>
+      <td class="number">${p.cs ?? "-"}</td>
+      <td class="number">${p.gold_earned.toLocaleString()}</td>
+      <td class="number">${p.damage_dealt.toLocaleString()}</td>
+      <td class="number">${p.vision_score}</td>
+      <td class="number">${p.op_score_rank || "-"}</td>
+      <td class="items">${esc((p.items || []).map((i) => i.name || i.id).join(", "))}</td>
+    </tr>`);
+  return `<h2 class="${team.is_win ? "win-text" : "loss-text"}">${esc(team.key)} team: ${team.is_win ? "Victory" : "Defeat"}</h2>
+    <p>${team.kills} / ${team.deaths} / ${team.assists}, ${team.gold_earned.toLocaleString()} gold, ${objectives}
+      ${team.firsts.length ? `<br><span class="muted">First: ${esc(team.firsts.join(", "))}</span>` : ""}
+      ${team.bans.length ? `<br><span class="muted">Bans: ${esc(team.bans.map((b) => b.name || b.id).join(", "))}</span>` : ""}</p>
+    <table>
+      <tr><th>Champion</th><th>Summoner</th><th>Position</th><th class="number">K / D / A</th><th class="number">CS</th><th class="number">Gold</th><th class="number">Damage</th><th class="number">Vision</th><th class="number">OP rank</th><th>Items</th></tr>
+      ${players.join("")}
+    </table>`;
+}
+
+async function renderGame(id) {
+  const board = await api("games/" + encodeURIComponent(id));
+  if (!(state.summoner in state.names)) {
+    const latest = await api("games?limit=1&" + summonerQuery());
+    state.names[state.summoner] = latest.length ? latest[0].summoner : "";
+  }
+  view.innerHTML = `<p><a href="#/">&larr; Match history</a></p>
+    <h1>${date(board.created_at)}</h1>
+    <p class="muted">${gameLength(board.game_length)}, patch ${esc(board.patch)} ${flags(board.flags)}</p>
+    ${board.teams.map((t) => renderTeam(t, state.names[state.summoner])).join("")}`;
+}
+
+const championFilters = { since: "", position: "", sort: "games" };
+
+async function renderChampions() {
+  const query = new URLSearchParams(championFilters);
+  if (state.summoner) {
+    query.set("summoner", state.summoner);
+  }
+  const champions = await api("stats/champions?" + query);
+  const options = (values, selected) =>
+    values.map(([value, label]) => `<option value="${value}" ${value === selected ? "selected" : ""}>${label}</option>`).join("");
+  const rows = champions.map((c) => `<tr>
+      <td>${championIcon({ id: c.champion_id })}${esc(c.champion)}</td>
+      <td class="number">${c.games}</td>
+      <td class="number">${percent(c.win_rate)}</td>
+      <td class="number">${fixed(c.kills, 1)} / ${fixed(c.deaths, 1)} / ${fixed(c.assists, 1)}</td>
+      <td class="number">${fixed(c.kda)}</td>
+      <td class="number">${fixed(c.cs_per_minute, 1)}</td>
+      <td class="number">${fixed(c.vision_score, 1)}</td>
+      <td class="number">${percent(c.kill_participation)}</td>
+      <td class="number">${percent(c.damage_share)}</td>
+      <td class="number">${fixed(c.op_score_rank, 1)}</td>
+    </tr>`);
+  view.innerHTML = `<h1>Champions</h1>
+    <div class="filters">
+      <label>Since <select data-filter="since">${options([["", "All games"], ["7d", "7 days"], ["30d", "30 days"], ["90d", "90 days"]], championFilters.since)}</select></label>
+      <label>Position <select data-filter="position">${options([["", "All"], ["TOP", "Top"], ["JUNGLE", "Jungle"], ["MID", "Mid"], ["ADC", "ADC"], ["SUPPORT", "Support"]], championFilters.position)}</select></label>
+      <label>Sort <select data-filter="sort">${options([["games", "Games"], ["winrate", "Win rate"], ["kda", "KDA"], ["opscore", "OP score"], ["cs", "CS"], ["vision", "Vision"], ["name", "Name"]], championFilters.sort)}</select></label>
+    </div>
+    ${champions.length === 0 ? `<p class="muted">No games match these filters.</p>` : `<table>
+      <tr><th>Champion</th><th class="number">Games</th><th class="number">Win rate</th><th class="number">K / D / A</th><th class="number">KDA</th><th class="number">CS/min</th><th class="number">Vision</th><th class="number">KP</th><th class="number">DMG%</th><th class="number">OP rank</th></tr>
+      ${rows.join("")}
+    </table>`}`;
+  view.querySelectorAll("select[data-filter]").forEach((select) => {
+    select.addEventListener("change", () => {
+      championFilters[select.dataset.filter] = select.value;
+      render();
+    });
+  });
+}
+
+async function render() {
+  const hash = location.hash.replace(/^#/, "") || "/";
+  const game = hash.match(/^\/games\/(.+)$/);
+  const current = hash === "/champions" ? "champions" : "games";
+  document.querySelectorAll("nav a").forEach((a) => a.classList.toggle("active", a.dataset.view === current));
+  try {
+    if (game) {
+      await renderGame(decodeURIComponent(game[1]));
+    } else if (hash === "/champions") {
+      await renderChampions();
+    } else {
+      await renderGames();
+    }
+  } catch (err) {
+    view.innerHTML = `<p class="loss-text">${esc(err.message)}</p>`;
+  }
+}
+
+async function loadSummoners() {
+  const select = document.getElementById("summoner");
+  const summoners = await api("summoners");
+  if (!summoners.some((s) => s.id === state.summoner)) {
+    state.summoner = summoners.length ? summoners[0].id : "";
+  }
+  select.innerHTML = summoners
+    .map((s) => `<option value="${esc(s.id)}" ${s.id === state.summoner ? "selected" : ""}>${esc(s.name || s.id)} (${esc(s.region)})</option>`)
+    .join("");
+  select.hidden = summoners.length < 2;
+}
+
+document.getElementById("summoner").addEventListener("change", (e) => {
+  state.summoner = e.target.value;
+  localStorage.setItem("summoner", state.summoner);
+  render();
+});
+
+document.getElementById("token-form").addEventListener("submit", (e) => {
+  e.preventDefault();
+  state.token = document.getElementById("token").value.trim();
+  localStorage.setItem("token", state.token);
+  e.target.hidden = true;
+  start();
+});
+
+window.addEventListener("hashchange", render);
+
+async function start() {
+  try {
+    await loadSummoners();
+  } catch (err) {
+    view.innerHTML = `<p class="loss-text">${esc(err.message)}</p>`;
+    return;
+  }
+  render();
+}
+
+start();
@@ -0,0 +1,37 @@
+// internal/web/web.go
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// Prefix is the path the API server serves the scripts and styles of the UI under
+const Prefix = "/ui"
+
+//go:embed static
+var static embed.FS
+
+// files holds index.html, app.js and style.css
+var files, _ = fs.Sub(static, "static")
+
+// Index serves the page of the web UI. The UI is a single page, the views are chosen by the URL fragment.
+func Index() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, files, "index.html")
+	})
+}
+
+// Files serves the scripts and styles of the web UI under Prefix
+func Files() http.Handler {
+	fileServer := http.StripPrefix(Prefix, http.FileServerFS(files))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The page loads its files relative to /, it does not work from the directory listing
+		if r.URL.Path == Prefix+"/" {
+			http.Redirect(w, r, "../", http.StatusFound)
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
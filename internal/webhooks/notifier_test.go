@@ -0,0 +1,195 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/models"
+)
+
+// delivery is a request received by the test receiver
+type delivery struct {
+	path string
+	body map[string]any
+}
+
+// newTestReceiver serves a Receiver failing the first failures deliveries, and records every delivery
+func newTestReceiver(t *testing.T, failures int) (*httptest.Server, *bytes.Buffer, func() []delivery) {
+	var out bytes.Buffer
+	receiver := NewReceiver(&out, failures)
+	var mu sync.Mutex
+	var deliveries []delivery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading delivery: %v", err)
+		}
+		d := delivery{path: r.URL.Path}
+		if err := json.Unmarshal(body, &d.body); err != nil {
+			t.Errorf("delivery to %s is not JSON: %v", r.URL.Path, err)
+		}
+		mu.Lock()
+		deliveries = append(deliveries, d)
+		mu.Unlock()
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		receiver.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &out, func() []delivery {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]delivery(nil), deliveries...)
+	}
+}
+
+func newTestNotifier(retries int, hooks ...config.WebhookConfig) *Notifier {
+	return New(&config.Config{
+		HTTPClient: config.HTTPClientConfig{Timeout: 5 * time.Second, UserAgent: "opggvisualizer-test"},
+		Webhooks: config.WebhooksConfig{
+			Retries:    retries,
+			RetryDelay: time.Millisecond,
+			BaseURL:    "http://stats.example.com",
+			Endpoints:  hooks,
+		},
+	})
+}
+
+var testGame = models.GameSummary{
+	GameID:     "game-1",
+	CreatedAt:  time.Date(2024, 11, 4, 18, 0, 0, 0, time.UTC),
+	GameLength: 1865,
+	Patch:      "14.20",
+	Summoner:   "Me",
+	Champion:   models.NamedID{ID: 103, Name: "Ahri"},
+	Position:   "MID",
+	Result:     "WIN",
+	Kills:      7,
+	Deaths:     2,
+	Assists:    9,
+}
+
+func TestNotifyGamePayloads(t *testing.T) {
+	server, _, deliveries := newTestReceiver(t, 0)
+	n := newTestNotifier(0,
+		config.WebhookConfig{Name: "json", URL: server.URL + "/json", Format: config.WebhookFormatJSON},
+		config.WebhookConfig{Name: "discord", URL: server.URL + "/discord", Format: config.WebhookFormatDiscord},
+		config.WebhookConfig{Name: "slack", URL: server.URL + "/slack", Format: config.WebhookFormatSlack},
+		config.WebhookConfig{Name: "other", URL: server.URL + "/other", Format: config.WebhookFormatJSON, Summoners: []string{"sid-other"}},
+	)
+	if err := n.NotifyGame(context.Background(), "sid-me", testGame); err != nil {
+		t.Fatalf("NotifyGame: %v", err)
+	}
+
+	got := deliveries()
+	if len(got) != 3 {
+		t.Fatalf("received %d deliveries, want 3 (the webhook of another summoner is skipped)", len(got))
+	}
+	bodies := map[string]map[string]any{}
+	for _, d := range got {
+		bodies[d.path] = d.body
+	}
+
+	event := bodies["/json"]
+	if event["event"] != EventGameIngested || event["summoner_id"] != "sid-me" || event["summary"] != "Me won as Ahri" {
+		t.Errorf("json payload = %v", event)
+	}
+	if event["url"] != "http://stats.example.com/#/games/game-1" || event["draft_url"] != "http://stats.example.com/games/game-1/draft.png" {
+		t.Errorf("json payload links = %v, %v", event["url"], event["draft_url"])
+	}
+	if game, _ := event["game"].(map[string]any); game["game_id"] != "game-1" || game["kills"] != 7.0 {
+		t.Errorf("json payload game = %v", event["game"])
+	}
+
+	discord := bodies["/discord"]
+	embeds, _ := discord["embeds"].([]any)
+	if discord["username"] != "opggvisualizer" || len(embeds) != 1 {
+		t.Fatalf("discord payload = %v", discord)
+	}
+	embed := embeds[0].(map[string]any)
+	if embed["title"] != "Me won as Ahri" || embed["url"] != "http://stats.example.com/#/games/game-1" {
+		t.Errorf("discord embed = %v", embed)
+	}
+	if embed["description"] != "7/2/9 · MID · 31:05 · patch 14.20" || embed["color"] != float64(discordWinColor) {
+		t.Errorf("discord embed description and color = %v, %v", embed["description"], embed["color"])
+	}
+	if image, _ := embed["image"].(map[string]any); image["url"] != "http://stats.example.com/games/game-1/draft.png" {
+		t.Errorf("discord embed image = %v", embed["image"])
+	}
+
+	slack := bodies["/slack"]
+	if want := "<http://stats.example.com/#/games/game-1|Me won as Ahri>\n7/2/9 · MID · 31:05 · patch 14.20"; slack["text"] != want || len(slack) != 1 {
+		t.Errorf("slack payload = %v, want only the text %q", slack, want)
+	}
+}
+
+func TestNewGameEventEscapesID(t *testing.T) {
+	game := testGame
+	game.GameID = "a/b?c"
+	event := NewGameEvent("http://stats.example.com", "sid-me", game)
+	if want := "http://stats.example.com/#/games/a%2Fb%3Fc"; event.URL != want {
+		t.Errorf("url = %s, want %s", event.URL, want)
+	}
+	if want := "http://stats.example.com/games/a%2Fb%3Fc/draft.png"; event.DraftURL != want {
+		t.Errorf("draft url = %s, want %s", event.DraftURL, want)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	server, out, deliveries := newTestReceiver(t, 1)
+	n := newTestNotifier(2)
+	hook := config.WebhookConfig{Name: "local", URL: server.URL + "/json", Format: config.WebhookFormatJSON}
+
+	if err := n.Send(context.Background(), hook, NewGameEvent("", "sid-me", testGame)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got := len(deliveries()); got != 2 {
+		t.Errorf("received %d deliveries, want 2 (a 503 then a retry)", got)
+	}
+	if !strings.Contains(out.String(), "answered 503") {
+		t.Errorf("receiver did not fail the first delivery:\n%s", out)
+	}
+}
+
+func TestSendGivesUpAfterRetries(t *testing.T) {
+	server, _, deliveries := newTestReceiver(t, 10)
+	n := newTestNotifier(2)
+	hook := config.WebhookConfig{Name: "local", URL: server.URL + "/json", Format: config.WebhookFormatJSON}
+
+	err := n.Send(context.Background(), hook, NewGameEvent("", "sid-me", testGame))
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("Send returned %v, want the 503", err)
+	}
+	if got := len(deliveries()); got != 3 {
+		t.Errorf("received %d deliveries, want 3 (the delivery and 2 retries)", got)
+	}
+}
+
+func TestSendGivesUpOnLongRetryAfter(t *testing.T) {
+	var deliveries int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveries++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+	n := newTestNotifier(2)
+	hook := config.WebhookConfig{Name: "local", URL: server.URL + "/json", Format: config.WebhookFormatJSON}
+
+	err := n.Send(context.Background(), hook, NewGameEvent("", "sid-me", testGame))
+	if err == nil || !strings.Contains(err.Error(), "giving up") {
+		t.Fatalf("Send returned %v, want it to give up on the Retry-After", err)
+	}
+	if deliveries != 1 {
+		t.Errorf("received %d deliveries, want 1", deliveries)
+	}
+}
@@ -0,0 +1,56 @@
+// internal/webhooks/receiver.go
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Receiver is a stand-in webhook receiver that writes every delivery to out. It is used to try the
+// webhooks locally before pointing them at Discord or Slack.
+type Receiver struct {
+	out      io.Writer
+	mu       sync.Mutex
+	failures int // Deliveries still to be answered with 503, to exercise the retries
+	received int
+}
+
+// NewReceiver returns a receiver that fails the first failures deliveries
+func NewReceiver(out io.Writer, failures int) *Receiver {
+	return &Receiver{out: out, failures: failures}
+}
+
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.received++
+	fmt.Fprintf(rc.out, "%s delivery %d: POST %s\n", time.Now().Format("15:04:05"), rc.received, r.URL.Path)
+	var indented bytes.Buffer
+	if json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	fmt.Fprintf(rc.out, "%s\n", body)
+
+	if rc.failures > 0 {
+		rc.failures--
+		fmt.Fprintln(rc.out, "answered 503")
+		http.Error(w, "Failing on purpose", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,221 @@
+// internal/webhooks/webhooks.go
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"opggvisualizer/internal/config"
+	"opggvisualizer/internal/models"
+)
+
+// EventGameIngested is sent for each new game stored by a games fetch
+const EventGameIngested = "game.ingested"
+
+// GameEvent is the body sent to webhooks in the json format
+type GameEvent struct {
+	Event      string             `json:"event"`
+	SummonerID string             `json:"summoner_id"`
+	Game       models.GameSummary `json:"game"`
+	Summary    string             `json:"summary"`   // One line description of the game, e.g. "Me won as Ahri"
+	URL        string             `json:"url"`       // Scoreboard of the game in the web UI
+	DraftURL   string             `json:"draft_url"` // Champions picked by both teams as an image
+}
+
+// NewGameEvent describes a new game of a summoner, with links relative to baseURL
+func NewGameEvent(baseURL, summonerID string, game models.GameSummary) GameEvent {
+	id := url.PathEscape(game.GameID)
+	return GameEvent{
+		Event:      EventGameIngested,
+		SummonerID: summonerID,
+		Game:       game,
+		Summary:    summary(game),
+		URL:        baseURL + "/#/games/" + id,
+		DraftURL:   baseURL + "/games/" + id + "/draft.png",
+	}
+}
+
+// summary describes the result of a game, e.g. "Me won as Ahri"
+func summary(game models.GameSummary) string {
+	outcome := "lost"
+	if game.Result == "WIN" {
+		outcome = "won"
+	}
+	if slices.Contains(game.Flags, models.FlagRemake) {
+		outcome = "remade a game"
+	}
+	champion := game.Champion.Name
+	if champion == "" {
+		champion = strconv.Itoa(game.Champion.ID)
+	}
+	return fmt.Sprintf("%s %s as %s", game.Summoner, outcome, champion)
+}
+
+// details lists the facts shown below the summary, e.g. "7/2/9 · MID · 31:05 · patch 14.20"
+func details(game models.GameSummary) string {
+	parts := []string{fmt.Sprintf("%d/%d/%d", game.Kills, game.Deaths, game.Assists)}
+	if game.Position != "" {
+		parts = append(parts, game.Position)
+	}
+	parts = append(parts, fmt.Sprintf("%d:%02d", game.GameLength/60, game.GameLength%60))
+	if game.Patch != "" {
+		parts = append(parts, "patch "+game.Patch)
+	}
+	if len(game.Flags) > 0 {
+		parts = append(parts, "flagged: "+strings.Join(game.Flags, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Embed colors used in Discord messages
+const (
+	discordWinColor  = 0x2e7d32
+	discordLossColor = 0xc62828
+)
+
+// Payload encodes event in a webhook format
+func Payload(format string, event GameEvent) ([]byte, error) {
+	switch format {
+	case config.WebhookFormatJSON:
+		return json.Marshal(event)
+	case config.WebhookFormatDiscord:
+		color := discordLossColor
+		if event.Game.Result == "WIN" {
+			color = discordWinColor
+		}
+		return json.Marshal(map[string]any{
+			"username": "opggvisualizer",
+			"embeds": []map[string]any{{
+				"title":       event.Summary,
+				"url":         event.URL,
+				"description": details(event.Game),
+				"color":       color,
+				"timestamp":   event.Game.CreatedAt.UTC().Format(time.RFC3339),
+				"image":       map[string]string{"url": event.DraftURL},
+			}},
+		})
+	case config.WebhookFormatSlack:
+		// Slack rejects the whole message if an image block cannot be downloaded, so the draft is only linked
+		return json.Marshal(map[string]any{
+			"text": fmt.Sprintf("<%s|%s>\n%s", event.URL, event.Summary, details(event.Game)),
+		})
+	}
+	return nil, fmt.Errorf("unknown webhook format %q", format)
+}
+
+// Notifier delivers game events to the configured webhooks
+type Notifier struct {
+	cfg       config.WebhooksConfig
+	userAgent string
+	http      *http.Client
+}
+
+func New(cfg *config.Config) *Notifier {
+	return &Notifier{
+		cfg:       cfg.Webhooks,
+		userAgent: cfg.HTTPClient.UserAgent,
+		http:      &http.Client{Timeout: cfg.HTTPClient.Timeout},
+	}
+}
+
+// Endpoints returns the webhooks notified about the games of a summoner
+func (n *Notifier) Endpoints(summonerID string) []config.WebhookConfig {
+	var hooks []config.WebhookConfig
+	for _, hook := range n.cfg.Endpoints {
+		if len(hook.Summoners) == 0 || slices.Contains(hook.Summoners, summonerID) {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+// NotifyGame sends a new game of a summoner to every webhook interested in the summoner
+func (n *Notifier) NotifyGame(ctx context.Context, summonerID string, game models.GameSummary) error {
+	event := NewGameEvent(n.cfg.BaseURL, summonerID, game)
+	var errs []error
+	for _, hook := range n.Endpoints(summonerID) {
+		if err := n.Send(ctx, hook, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// maxRetryAfterDelays bounds the Retry-After a receiver may ask for, in webhooks.retry_delay. A delivery asked to
+// wait longer is given up rather than holding the fetch.
+const maxRetryAfterDelays = 10
+
+// Send delivers event to one webhook. Network errors, 429 and 5xx responses are retried.
+func (n *Notifier) Send(ctx context.Context, hook config.WebhookConfig, event GameEvent) error {
+	body, err := Payload(hook.Format, event)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", hook.Name, err)
+	}
+
+	delay := n.cfg.RetryDelay
+	var lastErr error
+	for attempt := 0; attempt <= n.cfg.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("webhook %s: %w", hook.Name, ctx.Err())
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		retry, retryAfter, err := n.sendOnce(ctx, hook.URL, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+		if limit := maxRetryAfterDelays * n.cfg.RetryDelay; retryAfter > limit {
+			lastErr = fmt.Errorf("%w, giving up as the receiver asked to wait %s (more than %s)", err, retryAfter, limit)
+			break
+		}
+		delay = max(delay, retryAfter)
+	}
+	return fmt.Errorf("webhook %s: %w", hook.Name, lastErr)
+}
+
+// sendOnce posts body once and reports whether a failure is worth retrying, and how long the receiver asked to wait
+func (n *Notifier) sendOnce(ctx context.Context, endpoint string, body []byte) (bool, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.userAgent != "" {
+		req.Header.Set("User-Agent", n.userAgent)
+	}
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, 0, fmt.Errorf("HTTP POST request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return retry, retryAfter, fmt.Errorf("non-2xx HTTP status: %s", resp.Status)
+	}
+	return false, 0, nil
+}